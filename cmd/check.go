@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/monitor"
+)
+
+// Exit codes returned by the check command
+const (
+	exitOK       = 0
+	exitExpiring = 1
+	exitExpired  = 2
+	exitError    = 3
+)
+
+// runCheck runs a single certificate check, prints a summary and returns the exit code
+func runCheck() int {
+	log := logrus.WithField("component", "main")
+	log.Info("Running single certificate check")
+
+	// A broken installation must not look like expiring certificates
+	_, _, certMonitor, err := setup(log)
+	if err != nil {
+		log.WithError(err).Error("Failed to start the certificate check")
+		return exitError
+	}
+	defer certMonitor.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	summary, err := certMonitor.CheckOnce(ctx)
+	if err != nil {
+		log.WithError(err).Error("Certificate check failed")
+		return exitError
+	}
+
+	fmt.Fprintf(os.Stdout, "Certificates: %d\nExpired: %d\nExpiring: %d\nFailed: %d\nBroken issuers: %d\nExpiring issuers: %d\n",
+		summary.Total, summary.Expired, summary.Expiring, summary.Failed, summary.BrokenIssuers, summary.ExpiringIssuers)
+
+	return checkExitCode(summary)
+}
+
+// checkExitCode maps a check summary to the exit code of the check command
+func checkExitCode(summary monitor.CheckSummary) int {
+	switch {
+	case summary.Failed > 0:
+		return exitError
+	case summary.Expired > 0 || summary.BrokenIssuers > 0:
+		return exitExpired
+	case summary.Expiring > 0 || summary.ExpiringIssuers > 0:
+		return exitExpiring
+	default:
+		return exitOK
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/monitor"
+)
+
+func TestCheckExitCode(t *testing.T) {
+	tests := map[string]struct {
+		summary  monitor.CheckSummary
+		expected int
+	}{
+		"ok":       {monitor.CheckSummary{Total: 3}, exitOK},
+		"expiring": {monitor.CheckSummary{Total: 3, Expiring: 1}, exitExpiring},
+		"expired":  {monitor.CheckSummary{Total: 3, Expiring: 1, Expired: 1}, exitExpired},
+		"failed":   {monitor.CheckSummary{Total: 3, Expired: 1, Failed: 1}, exitError},
+
+		"broken issuer":   {monitor.CheckSummary{Total: 3, Expiring: 1, BrokenIssuers: 1}, exitExpired},
+		"expiring issuer": {monitor.CheckSummary{Total: 3, ExpiringIssuers: 1}, exitExpiring},
+	}
+
+	for name, test := range tests {
+		if code := checkExitCode(test.summary); code != test.expected {
+			t.Errorf("%s: expected exit code %d, got %d", name, test.expected, code)
+		}
+	}
+}
@@ -2,18 +2,27 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/kubernetes"
 
+	"github.com/wiruzman/cert-manager-notifier/internal/api"
+	"github.com/wiruzman/cert-manager-notifier/internal/cluster"
 	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/dashboard"
 	"github.com/wiruzman/cert-manager-notifier/internal/health"
 	"github.com/wiruzman/cert-manager-notifier/internal/monitor"
+	"github.com/wiruzman/cert-manager-notifier/internal/reload"
+	"github.com/wiruzman/cert-manager-notifier/internal/shard"
+	"github.com/wiruzman/cert-manager-notifier/internal/silence"
 	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
 )
 
@@ -22,28 +31,54 @@ func main() {
 	logrus.SetFormatter(&logrus.JSONFormatter{})
 	logrus.SetLevel(logrus.InfoLevel)
 
-	log := logrus.WithField("component", "main")
-	log.Info("Starting cert-manager-notifier")
+	flag.Usage = usage
+	once := flag.Bool("once", false, "Run a single certificate check and exit (same as the check command)")
+	flag.Parse()
 
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.WithError(err).Fatal("Failed to load configuration")
+	command := flag.Arg(0)
+	if *once {
+		command = "check"
 	}
 
-	// Create Kubernetes client
-	k8sConfig, err := getKubernetesConfig()
-	if err != nil {
-		log.WithError(err).Fatal("Failed to get Kubernetes config")
+	switch command {
+	case "", "run":
+		run()
+	case "check":
+		os.Exit(runCheck())
+	case "report":
+		os.Exit(runReport(flag.Args()[1:]))
+	case "test-webhook":
+		os.Exit(runTestWebhook(flag.Args()[1:]))
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", command)
+		usage()
+		os.Exit(2)
 	}
+}
 
-	// Create webhook notifier
-	webhookNotifier := webhook.NewNotifier(cfg.Webhooks, log)
+// usage prints the available commands
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: cert-manager-notifier [flags] [command]
+
+Commands:
+  run           Monitor certificates continuously (default)
+  check         Run a single certificate check and exit with 0 (ok), 1 (expiring), 2 (expired) or 3 (error)
+  report        List certificates without sending notifications (see report -h)
+  test-webhook  Send synthetic notifications to the configured webhooks (see test-webhook -h)
 
-	// Create certificate monitor
-	certMonitor, err := monitor.NewCertificateMonitor(k8sConfig, cfg, webhookNotifier, log)
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+// run monitors certificates until a shutdown signal is received
+func run() {
+	log := logrus.WithField("component", "main")
+	log.Info("Starting cert-manager-notifier")
+
+	cfg, webhookNotifier, certMonitor, err := setup(log)
 	if err != nil {
-		log.WithError(err).Fatal("Failed to create certificate monitor")
+		log.WithError(err).Fatal("Failed to start")
 	}
 
 	// Setup graceful shutdown
@@ -55,15 +90,44 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	// Start health check server
-	healthServer := health.NewHealthServer(cfg.HealthPort)
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register("kubernetes", health.Readiness, certMonitor.SyncStatus)
+	healthRegistry.Register("certificate-checks", health.Readiness|health.Liveness, certMonitor.CheckStatus)
+	healthRegistry.Register("webhooks", health.Readiness, webhookNotifier.Reachable)
+
+	if cfg.ShardingEnabled {
+		coordinator := newShardCoordinator(cfg, log)
+		certMonitor.EnableSharding(coordinator)
+		healthRegistry.Register("sharding", health.Readiness, coordinator.Status)
+	}
+
+	healthServer := health.NewHealthServer(cfg.HealthPort, healthRegistry)
+	apiAuth := api.NewCredentials(dashboardAuth(cfg))
+	api.NewHandler(certMonitor, certMonitor.Silences(), webhookNotifier.History(), apiAuth, log).Register(healthServer)
+	if cfg.DashboardEnabled {
+		if err := dashboard.Register(healthServer, apiAuth); err != nil {
+			log.WithError(err).Fatal("Failed to register dashboard")
+		}
+	}
 	go func() {
 		if err := healthServer.Start(); err != nil {
 			log.WithError(err).Error("Health server failed")
 		}
 	}()
 
-	// Set initial health status
-	health.SetHealthy(true)
+	// Reload the configuration on SIGHUP and when the config directories change
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+	// The monitor swaps in the webhooks together with the other settings,
+	// while rotated dashboard credentials apply to the next request
+	reloader := reload.NewReloader(cfg, loadConfig, func(reloaded *config.Config) error {
+		if err := certMonitor.Reconfigure(reloaded); err != nil {
+			return err
+		}
+		apiAuth.Set(dashboardAuth(reloaded))
+		return nil
+	}, log)
+	go reloader.Run(ctx, reloadChan)
 
 	// Start certificate monitor
 	go func() {
@@ -77,29 +141,168 @@ func main() {
 	<-sigChan
 	log.Info("Shutting down...")
 
-	// Set health status to unhealthy
-	health.SetHealthy(false)
+	// Fail health probes while shutting down
+	healthRegistry.SetShuttingDown()
 
 	// Cancel context to stop all operations
 	cancel()
 
 	// Give some time for graceful shutdown
 	time.Sleep(2 * time.Second)
+	certMonitor.Close()
 	log.Info("Shutdown complete")
 }
 
-func getKubernetesConfig() (*rest.Config, error) {
-	// Try in-cluster config first
-	config, err := rest.InClusterConfig()
-	if err == nil {
-		return config, nil
+// dashboardAuth returns the credentials protecting the dashboard and API
+func dashboardAuth(cfg *config.Config) api.Auth {
+	return api.Auth{
+		Token:    cfg.DashboardToken,
+		Username: cfg.DashboardUsername,
+		Password: cfg.DashboardPassword,
+	}
+}
+
+// setup loads the configuration and creates the notifier and certificate
+// monitor. Errors are returned rather than fatal, so that the check command
+// can report them with its own exit code.
+func setup(log *logrus.Entry) (*config.Config, *webhook.Notifier, *monitor.Fleet, error) {
+	// Load configuration
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Create webhook notifier
+	webhookNotifier := webhook.NewNotifier(cfg.Webhooks, log)
+	if cfg.DryRun {
+		if err := enableDryRun(cfg, webhookNotifier, log); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	certMonitor, err := newCertificateMonitor(cfg, webhookNotifier, log)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cfg, webhookNotifier, certMonitor, nil
+}
+
+// loadConfig loads the configuration, reading the Secrets referenced by
+// *_SECRET_REF settings from the local cluster
+func loadConfig() (*config.Config, error) {
+	return config.LoadWithSecrets(readLocalSecret)
+}
+
+var (
+	localSecretsOnce sync.Once
+	localSecrets     config.SecretReader
+	localSecretsErr  error
+)
+
+// readLocalSecret reads a Secret key from the local cluster, connecting on
+// first use so that configurations without Secret references need no access
+func readLocalSecret(ctx context.Context, namespace, name, key string) (string, error) {
+	localSecretsOnce.Do(func() {
+		k8sConfig, err := cluster.Local()
+		if err != nil {
+			localSecretsErr = fmt.Errorf("failed to get Kubernetes config: %w", err)
+			return
+		}
+		client, err := kubernetes.NewForConfig(k8sConfig)
+		if err != nil {
+			localSecretsErr = fmt.Errorf("failed to create kubernetes client: %w", err)
+			return
+		}
+		localSecrets = config.KubernetesSecrets(client)
+	})
+	if localSecretsErr != nil {
+		return "", localSecretsErr
+	}
+	return localSecrets(ctx, namespace, name, key)
+}
+
+// enableDryRun makes the notifier log notifications instead of sending them
+func enableDryRun(cfg *config.Config, webhookNotifier *webhook.Notifier, log *logrus.Entry) error {
+	var output io.Writer
+	if cfg.DryRunOutput != "" {
+		file, err := os.OpenFile(cfg.DryRunOutput, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open dry run output: %w", err)
+		}
+		output = file
+	}
+
+	webhookNotifier.EnableDryRun(output)
+	log.WithField("output", cfg.DryRunOutput).Warn("Dry run mode enabled, notifications will not be sent")
+	return nil
+}
+
+// newShardCoordinator creates the coordinator sharing namespaces with the
+// other replicas through Leases in the local cluster
+func newShardCoordinator(cfg *config.Config, log *logrus.Entry) *shard.Coordinator {
+	if cfg.ShardID == "" {
+		log.Fatal("SHARD_ID is required when sharding is enabled")
+	}
+
+	k8sConfig, err := cluster.Local()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to get Kubernetes config")
+	}
+
+	client, err := kubernetes.NewForConfig(k8sConfig)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to create kubernetes client")
 	}
 
-	// Fall back to kubeconfig
-	kubeconfig := os.Getenv("KUBECONFIG")
-	if kubeconfig == "" {
-		kubeconfig = os.Getenv("HOME") + "/.kube/config"
+	log.WithField("shard_id", cfg.ShardID).WithField("shard_group", cfg.ShardGroup).Info("Sharding enabled")
+	return shard.NewCoordinator(client, cfg.ShardNamespace, cfg.ShardGroup, cfg.ShardID, cfg.ShardLeaseDuration, log)
+}
+
+// newCertificateMonitor creates a certificate monitor for each configured cluster
+func newCertificateMonitor(cfg *config.Config, webhookNotifier *webhook.Notifier, log *logrus.Entry) (*monitor.Fleet, error) {
+	// Resolve the clusters to monitor
+	clusters, err := cluster.Load(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cluster configuration: %w", err)
+	}
+
+	// Create certificate monitors
+	certMonitor, err := monitor.NewFleet(clusters, cfg, webhookNotifier, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate monitor: %w", err)
+	}
+
+	if len(clusters) > 1 {
+		log.WithField("clusters", len(clusters)).Info("Monitoring multiple clusters")
+	}
+
+	// Silences are kept in the local cluster, whichever clusters are monitored
+	if cfg.SilencesConfigMap != "" {
+		backend, err := newSilenceBackend(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to persist silences: %w", err)
+		}
+		certMonitor.PersistSilences(backend)
+	}
+
+	return certMonitor, nil
+}
+
+// newSilenceBackend creates the backend persisting silences in a ConfigMap
+// in the local cluster
+func newSilenceBackend(cfg *config.Config) (*silence.ConfigMap, error) {
+	k8sConfig, err := cluster.Local()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Kubernetes config: %w", err)
+	}
+
+	client, err := kubernetes.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
 
-	return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	return silence.NewConfigMap(client, cfg.SilencesNamespace, cfg.SilencesConfigMap), nil
 }
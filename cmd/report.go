@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/report"
+)
+
+// runReport lists certificates in the requested format and returns the exit code
+func runReport(args []string) int {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	output := flags.String("output", report.FormatTable, "Output format: table, json, csv or markdown")
+	sortBy := flags.String("sort", report.SortByExpiry, "Sort by: expiry or namespace")
+	expiringWithin := flags.String("expiring-within", "", "Only include certificates expiring within this duration (e.g. 72h or 30d)")
+	namespace := flags.String("namespace", "", "Namespace to report on (defaults to NAMESPACE, empty means all namespaces)")
+	_ = flags.Parse(args)
+
+	log := logrus.WithField("component", "main")
+
+	var within time.Duration
+	if *expiringWithin != "" {
+		duration, err := config.ParseDuration(*expiringWithin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --expiring-within: %v\n", err)
+			return exitError
+		}
+		within = duration
+	}
+
+	cfg, err := config.LoadWithoutWebhooks()
+	if err != nil {
+		log.WithError(err).Error("Failed to load configuration")
+		return exitError
+	}
+	if *namespace != "" {
+		cfg.Namespace = *namespace
+	}
+
+	// The report never sends notifications, so no notifier is needed
+	certMonitor, err := newCertificateMonitor(cfg, nil, log)
+	if err != nil {
+		log.WithError(err).Error("Failed to start the report")
+		return exitError
+	}
+
+	// Clusters that cannot be listed fail the report, but the certificates
+	// of the other clusters are still written
+	certificates, listErr := certMonitor.Report(context.Background())
+	if listErr != nil {
+		log.WithError(listErr).Error("Failed to list certificates")
+	}
+
+	now := time.Now()
+	certificates = report.Filter(certificates, within, now)
+	if err := report.Sort(certificates, *sortBy); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	if err := report.Write(os.Stdout, *output, certificates, now); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	if listErr != nil {
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// runTestWebhook sends synthetic notifications to the configured webhooks and returns the exit code
+func runTestWebhook(args []string) int {
+	flags := flag.NewFlagSet("test-webhook", flag.ExitOnError)
+	name := flags.String("webhook", "", "Name of the webhook to test (e.g. webhook-1, defaults to all webhooks)")
+	notificationType := flags.String("type", "", "Notification type to send (defaults to all types)")
+	dryRun := flags.Bool("dry-run", false, "Print the rendered requests, with secrets redacted, instead of sending them")
+	_ = flags.Parse(args)
+
+	log := logrus.WithField("component", "main")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.WithError(err).Error("Failed to load configuration")
+		return exitError
+	}
+
+	webhookNotifier := webhook.NewNotifier(cfg.Webhooks, log)
+
+	var payloads []webhook.NotificationPayload
+	for _, payload := range webhook.SamplePayloads() {
+		if *notificationType == "" || payload.Type == *notificationType {
+			payloads = append(payloads, payload)
+		}
+	}
+	if len(payloads) == 0 {
+		fmt.Fprintf(os.Stderr, "Unknown notification type %q\n", *notificationType)
+		return exitError
+	}
+
+	if *dryRun {
+		return printRenderedRequests(webhookNotifier, payloads, *name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	failed := false
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WEBHOOK\tTYPE\tSTATUS\tLATENCY\tRESULT")
+	for _, payload := range payloads {
+		results, err := webhookNotifier.SendTest(ctx, payload, *name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+
+		for _, result := range results {
+			outcome := strings.TrimSpace(result.ResponseBody)
+			if result.Err != nil {
+				failed = true
+				outcome = result.Err.Error()
+				if body := strings.TrimSpace(result.ResponseBody); body != "" {
+					outcome += ": " + body
+				}
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", result.Webhook, payload.Type, result.StatusCode, result.Latency.Round(time.Millisecond), outcome)
+		}
+	}
+	_ = tw.Flush()
+
+	if failed {
+		return exitError
+	}
+	return exitOK
+}
+
+// printRenderedRequests prints the requests that would be sent for each payload
+func printRenderedRequests(webhookNotifier *webhook.Notifier, payloads []webhook.NotificationPayload, name string) int {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	for _, payload := range payloads {
+		rendered, err := webhookNotifier.RenderRequests(payload, name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+
+		for _, request := range rendered {
+			if err := encoder.Encode(request.Redacted()); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return exitError
+			}
+		}
+	}
+
+	return exitOK
+}
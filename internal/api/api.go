@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/monitor"
+	"github.com/wiruzman/cert-manager-notifier/internal/silence"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// Router registers HTTP handler functions
+type Router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// CertificateSource provides the monitor's view of certificates
+type CertificateSource interface {
+	Certificates() []monitor.CertificateStatus
+}
+
+// Handler serves the notifier HTTP API
+type Handler struct {
+	certificates CertificateSource
+	silences     *silence.Store
+	history      *webhook.History
+	auth         *Credentials
+	logger       *logrus.Entry
+}
+
+// NewHandler creates a new API handler
+func NewHandler(certificates CertificateSource, silences *silence.Store, history *webhook.History, auth *Credentials, logger *logrus.Entry) *Handler {
+	return &Handler{
+		certificates: certificates,
+		silences:     silences,
+		history:      history,
+		auth:         auth,
+		logger:       logger.WithField("component", "api"),
+	}
+}
+
+// Register registers the API routes on the router
+func (h *Handler) Register(router Router) {
+	router.HandleFunc("GET /api/v1/certificates", h.auth.Wrap(h.listCertificates))
+	router.HandleFunc("GET /api/v1/certificates/{namespace}/{name}", h.auth.Wrap(h.getCertificate))
+	router.HandleFunc("GET /api/v1/notifications", h.auth.Wrap(h.listNotifications))
+	router.HandleFunc("GET /api/v1/silences", h.auth.Wrap(h.listSilences))
+	router.HandleFunc("POST /api/v1/silences", h.auth.WrapMutating(h.createSilence))
+	router.HandleFunc("DELETE /api/v1/silences/{id}", h.auth.WrapMutating(h.deleteSilence))
+}
+
+// errorResponse is the body returned for failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes a JSON response with the given status code
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.WithError(err).Error("Failed to write response")
+	}
+}
+
+// writeError writes a JSON error response
+func (h *Handler) writeError(w http.ResponseWriter, status int, message string) {
+	h.writeJSON(w, status, errorResponse{Error: message})
+}
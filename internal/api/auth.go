@@ -0,0 +1,128 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"sync"
+)
+
+// Auth holds the optional credentials protecting the API and dashboard
+type Auth struct {
+	Token    string
+	Username string
+	Password string
+}
+
+// Enabled reports whether any credentials are configured
+func (a Auth) Enabled() bool {
+	return a.Token != "" || a.BasicEnabled()
+}
+
+// BasicEnabled reports whether basic auth credentials are configured
+func (a Auth) BasicEnabled() bool {
+	return a.Username != "" && a.Password != ""
+}
+
+// Credentials holds the Auth protecting the API and dashboard. It is checked
+// on every request, so replacing it, e.g. after a rotated Secret was
+// reloaded, takes effect without a restart.
+type Credentials struct {
+	mutex sync.RWMutex
+	auth  Auth
+}
+
+// NewCredentials creates credentials protecting with auth
+func NewCredentials(auth Auth) *Credentials {
+	return &Credentials{auth: auth}
+}
+
+// Get returns the Auth in effect
+func (c *Credentials) Get() Auth {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.auth
+}
+
+// Set replaces the Auth for the requests from now on
+func (c *Credentials) Set(auth Auth) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.auth = auth
+}
+
+// Wrap rejects requests without valid credentials when auth is enabled
+func (c *Credentials) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth := c.Get()
+		if !auth.Enabled() || auth.authorized(r) {
+			next(w, r)
+			return
+		}
+
+		if auth.BasicEnabled() {
+			w.Header().Set("WWW-Authenticate", `Basic realm="cert-manager-notifier"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		}
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+}
+
+// WrapBasic rejects requests without valid credentials only while basic auth
+// is enabled
+func (c *Credentials) WrapBasic(next http.HandlerFunc) http.HandlerFunc {
+	wrapped := c.Wrap(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !c.Get().BasicEnabled() {
+			next(w, r)
+			return
+		}
+		wrapped(w, r)
+	}
+}
+
+// WrapMutating protects endpoints that change state, such as silences,
+// which could suppress every alert. Without credentials configured they are
+// refused rather than left open to anyone who can reach the port.
+func (c *Credentials) WrapMutating(next http.HandlerFunc) http.HandlerFunc {
+	wrapped := c.Wrap(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !c.Get().Enabled() {
+			http.Error(w, "Forbidden: configure DASHBOARD_TOKEN or DASHBOARD_USERNAME and DASHBOARD_PASSWORD to enable this endpoint", http.StatusForbidden)
+			return
+		}
+		wrapped(w, r)
+	}
+}
+
+// authorized checks the request credentials against the configured ones
+func (a Auth) authorized(r *http.Request) bool {
+	if a.Token != "" {
+		if token, ok := bearerToken(r); ok && secureEqual(token, a.Token) {
+			return true
+		}
+	}
+
+	if a.BasicEnabled() {
+		if username, password, ok := r.BasicAuth(); ok && secureEqual(username, a.Username) && secureEqual(password, a.Password) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bearerToken extracts the bearer token from the Authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(prefix) || header[:len(prefix)] != prefix {
+		return "", false
+	}
+	return header[len(prefix):], true
+}
+
+// secureEqual compares two strings in constant time
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuth_Wrap(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	auth := NewCredentials(Auth{Token: "secret-token", Username: "admin", Password: "secret-password"})
+	wrapped := auth.Wrap(handler)
+
+	tests := map[string]struct {
+		setup    func(r *http.Request)
+		expected int
+	}{
+		"no credentials": {func(r *http.Request) {}, http.StatusUnauthorized},
+		"valid token":    {func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret-token") }, http.StatusOK},
+		"invalid token":  {func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") }, http.StatusUnauthorized},
+		"valid basic":    {func(r *http.Request) { r.SetBasicAuth("admin", "secret-password") }, http.StatusOK},
+		"invalid basic":  {func(r *http.Request) { r.SetBasicAuth("admin", "wrong") }, http.StatusUnauthorized},
+	}
+
+	for name, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/certificates", nil)
+		test.setup(req)
+		rec := httptest.NewRecorder()
+		wrapped(rec, req)
+
+		if rec.Code != test.expected {
+			t.Errorf("%s: expected status %d, got %d", name, test.expected, rec.Code)
+		}
+	}
+}
+
+func TestAuth_Disabled(t *testing.T) {
+	wrapped := NewCredentials(Auth{}).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	wrapped(rec, httptest.NewRequest(http.MethodGet, "/api/v1/certificates", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 without auth configured, got %d", rec.Code)
+	}
+}
+
+func TestAuth_WrapMutating(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	rec := httptest.NewRecorder()
+	NewCredentials(Auth{}).WrapMutating(handler)(rec, httptest.NewRequest(http.MethodPost, "/api/v1/silences", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 without auth configured, got %d", rec.Code)
+	}
+
+	auth := NewCredentials(Auth{Token: "secret-token"})
+	rec = httptest.NewRecorder()
+	auth.WrapMutating(handler)(rec, httptest.NewRequest(http.MethodPost, "/api/v1/silences", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without credentials, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/silences", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	rec = httptest.NewRecorder()
+	auth.WrapMutating(handler)(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status 201 with a valid token, got %d", rec.Code)
+	}
+}
+
+func TestCredentials_Set(t *testing.T) {
+	credentials := NewCredentials(Auth{Token: "old-token"})
+	wrapped := credentials.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	request := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/certificates", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		wrapped(rec, req)
+		return rec.Code
+	}
+
+	if code := request("old-token"); code != http.StatusOK {
+		t.Errorf("Expected status 200 with the old token, got %d", code)
+	}
+
+	// Rotated credentials apply to handlers wrapped before
+	credentials.Set(Auth{Token: "new-token"})
+	if code := request("old-token"); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 with the rotated token, got %d", code)
+	}
+	if code := request("new-token"); code != http.StatusOK {
+		t.Errorf("Expected status 200 with the new token, got %d", code)
+	}
+}
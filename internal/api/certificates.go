@@ -0,0 +1,113 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/monitor"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// certificateDetail is a certificate together with its last notification per webhook
+type certificateDetail struct {
+	monitor.CertificateStatus
+	Notifications map[string]webhook.Delivery `json:"notifications"`
+}
+
+// listCertificates returns the tracked certificates, optionally filtered by
+// cluster, namespace, state and expiring_within
+func (h *Handler) listCertificates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	cluster := query.Get("cluster")
+	namespace := query.Get("namespace")
+	state := query.Get("state")
+
+	var within time.Duration
+	if val := query.Get("expiring_within"); val != "" {
+		duration, err := config.ParseDuration(val)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid expiring_within: "+err.Error())
+			return
+		}
+		within = duration
+	}
+
+	now := time.Now()
+	certificates := make([]monitor.CertificateStatus, 0)
+	for _, cert := range h.certificates.Certificates() {
+		if cluster != "" && cert.Cluster != cluster {
+			continue
+		}
+		if namespace != "" && cert.Namespace != namespace {
+			continue
+		}
+		if state != "" && cert.State != state {
+			continue
+		}
+		if within > 0 && (cert.ExpiresAt == nil || cert.ExpiresAt.After(now.Add(within))) {
+			continue
+		}
+		certificates = append(certificates, cert)
+	}
+
+	h.writeJSON(w, http.StatusOK, certificates)
+}
+
+// getCertificate returns a single certificate and its last notification per
+// webhook. The cluster query parameter selects the cluster when monitoring several.
+func (h *Handler) getCertificate(w http.ResponseWriter, r *http.Request) {
+	cluster := r.URL.Query().Get("cluster")
+	namespace := r.PathValue("namespace")
+	name := r.PathValue("name")
+
+	for _, cert := range h.certificates.Certificates() {
+		if cert.Cluster == cluster && cert.Namespace == namespace && cert.Name == name {
+			h.writeJSON(w, http.StatusOK, certificateDetail{
+				CertificateStatus: cert,
+				Notifications:     h.history.LastByWebhook(cluster, namespace, name),
+			})
+			return
+		}
+	}
+
+	h.writeError(w, http.StatusNotFound, "certificate not found")
+}
+
+// listNotifications returns the recent delivery history, newest first
+func (h *Handler) listNotifications(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	cluster := query.Get("cluster")
+	namespace := query.Get("namespace")
+	name := query.Get("name")
+
+	limit := 100
+	if val := query.Get("limit"); val != "" {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed <= 0 {
+			h.writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries := make([]webhook.Delivery, 0)
+	for _, delivery := range h.history.List() {
+		if cluster != "" && delivery.Cluster != cluster {
+			continue
+		}
+		if namespace != "" && delivery.Namespace != namespace {
+			continue
+		}
+		if name != "" && delivery.Name != name {
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+		if len(deliveries) == limit {
+			break
+		}
+	}
+
+	h.writeJSON(w, http.StatusOK, deliveries)
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/monitor"
+	"github.com/wiruzman/cert-manager-notifier/internal/silence"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// fakeCertificates is a static CertificateSource
+type fakeCertificates struct {
+	certificates []monitor.CertificateStatus
+}
+
+func (f *fakeCertificates) Certificates() []monitor.CertificateStatus {
+	return f.certificates
+}
+
+func newTestMux(certificates []monitor.CertificateStatus, history *webhook.History) *http.ServeMux {
+	mux := http.NewServeMux()
+	NewHandler(&fakeCertificates{certificates: certificates}, silence.NewStore(), history, NewCredentials(Auth{}), logrus.NewEntry(logrus.New())).Register(mux)
+	return mux
+}
+
+func TestHandler_ListCertificates(t *testing.T) {
+	soon := time.Now().Add(48 * time.Hour)
+	later := time.Now().Add(60 * 24 * time.Hour)
+
+	mux := newTestMux([]monitor.CertificateStatus{
+		{Namespace: "default", Name: "api", State: monitor.StateExpiring, ExpiresAt: &soon},
+		{Namespace: "default", Name: "web", State: monitor.StateOK, ExpiresAt: &later},
+		{Namespace: "other", Name: "db", State: monitor.StateOK, ExpiresAt: &later},
+		{Cluster: "prod", Namespace: "default", Name: "web", State: monitor.StateOK, ExpiresAt: &later},
+	}, webhook.NewHistory(10))
+
+	tests := map[string]int{
+		"/api/v1/certificates":                          4,
+		"/api/v1/certificates?namespace=default":        3,
+		"/api/v1/certificates?cluster=prod":             1,
+		"/api/v1/certificates?state=expiring":           1,
+		"/api/v1/certificates?expiring_within=7d":       1,
+		"/api/v1/certificates?expiring_within=2160h":    4,
+		"/api/v1/certificates?namespace=other&state=ok": 1,
+	}
+
+	for url, expected := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+
+		var certificates []monitor.CertificateStatus
+		if err := json.NewDecoder(rec.Body).Decode(&certificates); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", url, err)
+		}
+		if len(certificates) != expected {
+			t.Errorf("%s: expected %d certificates, got %d", url, expected, len(certificates))
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/certificates?expiring_within=soon", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid filter, got %d", rec.Code)
+	}
+}
+
+func TestHandler_GetCertificate(t *testing.T) {
+	history := webhook.NewHistory(10)
+	history.Add(webhook.Delivery{Webhook: "webhook-1", Namespace: "default", Name: "api", Type: "expiring", Success: true})
+
+	mux := newTestMux([]monitor.CertificateStatus{
+		{Namespace: "default", Name: "api", State: monitor.StateExpiring},
+	}, history)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/certificates/default/api", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var detail certificateDetail
+	if err := json.NewDecoder(rec.Body).Decode(&detail); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !detail.Notifications["webhook-1"].Success {
+		t.Errorf("Expected last notification for webhook-1, got %+v", detail.Notifications)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/certificates/default/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandler_ListNotifications(t *testing.T) {
+	history := webhook.NewHistory(10)
+	history.Add(webhook.Delivery{Webhook: "webhook-1", Namespace: "default", Name: "api"})
+	history.Add(webhook.Delivery{Webhook: "webhook-1", Namespace: "other", Name: "db"})
+	history.Add(webhook.Delivery{Webhook: "webhook-2", Namespace: "default", Name: "api"})
+
+	mux := newTestMux(nil, history)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications?namespace=default&limit=1", nil))
+
+	var deliveries []webhook.Delivery
+	if err := json.NewDecoder(rec.Body).Decode(&deliveries); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Webhook != "webhook-2" {
+		t.Errorf("Expected newest delivery for default namespace, got %+v", deliveries)
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/silence"
+)
+
+// listSilences returns all active silences, including those added through
+// other replicas when the silences are persisted
+func (h *Handler) listSilences(w http.ResponseWriter, r *http.Request) {
+	if err := h.silences.Sync(r.Context()); err != nil {
+		h.logger.WithError(err).Warn("Failed to refresh silences")
+	}
+	h.writeJSON(w, http.StatusOK, h.silences.List(time.Now()))
+}
+
+// createSilence creates a new silence from the request body
+func (h *Handler) createSilence(w http.ResponseWriter, r *http.Request) {
+	var request silence.Silence
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	now := time.Now()
+	if err := request.Validate(now); err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := h.silences.Add(r.Context(), request, now)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create silence")
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.logger.WithField("silence", created.ID).WithField("author", created.Author).WithField("expires_at", created.ExpiresAt).Info("Silence created")
+	h.writeJSON(w, http.StatusCreated, created)
+}
+
+// deleteSilence removes a silence by ID
+func (h *Handler) deleteSilence(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	deleted, err := h.silences.Delete(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).WithField("silence", id).Error("Failed to delete silence")
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !deleted {
+		h.writeError(w, http.StatusNotFound, "silence not found")
+		return
+	}
+
+	h.logger.WithField("silence", id).Info("Silence deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
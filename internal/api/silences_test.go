@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/silence"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// testToken is the API token the silences tests authenticate with
+const testToken = "secret-token"
+
+// withToken adds the test API token to a request
+func withToken(req *http.Request) *http.Request {
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	return req
+}
+
+func TestHandler_Silences(t *testing.T) {
+	store := silence.NewStore()
+	mux := http.NewServeMux()
+	NewHandler(&fakeCertificates{}, store, webhook.NewHistory(10), NewCredentials(Auth{Token: testToken}), logrus.NewEntry(logrus.New())).Register(mux)
+
+	// Create silence
+	body, _ := json.Marshal(map[string]interface{}{
+		"matchers":   []map[string]string{{"name": "namespace", "value": "default"}},
+		"author":     "alice",
+		"comment":    "renewal in progress",
+		"expires_at": time.Now().Add(time.Hour),
+	})
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, withToken(httptest.NewRequest(http.MethodPost, "/api/v1/silences", bytes.NewReader(body))))
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var created silence.Silence
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	// List silences
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, withToken(httptest.NewRequest(http.MethodGet, "/api/v1/silences", nil)))
+
+	var silences []silence.Silence
+	if err := json.NewDecoder(rec.Body).Decode(&silences); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(silences) != 1 || silences[0].ID != created.ID {
+		t.Errorf("Expected created silence in list, got %+v", silences)
+	}
+
+	// Delete silence
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, withToken(httptest.NewRequest(http.MethodDelete, "/api/v1/silences/"+created.ID, nil)))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, withToken(httptest.NewRequest(http.MethodDelete, "/api/v1/silences/"+created.ID, nil)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandler_CreateSilenceInvalid(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(&fakeCertificates{}, silence.NewStore(), webhook.NewHistory(10), NewCredentials(Auth{Token: testToken}), logrus.NewEntry(logrus.New())).Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, withToken(httptest.NewRequest(http.MethodPost, "/api/v1/silences", bytes.NewBufferString(`{"author":"alice"}`))))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
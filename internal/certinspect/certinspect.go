@@ -0,0 +1,210 @@
+package certinspect
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Expected holds the certificate attributes requested in a Certificate resource
+type Expected struct {
+	CommonName     string
+	DNSNames       []string
+	IPAddresses    []string
+	URIs           []string
+	EmailAddresses []string
+	NotAfter       *time.Time
+}
+
+// ParseCertificates parses all PEM encoded certificates in data, in order
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate %d: %w", len(certificates)+1, err)
+		}
+		certificates = append(certificates, cert)
+	}
+
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificates found")
+	}
+
+	return certificates, nil
+}
+
+// Compare returns a description of every difference between the expected
+// attributes and the issued leaf certificate
+func Compare(expected Expected, leaf *x509.Certificate) []string {
+	var mismatches []string
+
+	if expected.NotAfter != nil && !expected.NotAfter.Equal(leaf.NotAfter) {
+		mismatches = append(mismatches, fmt.Sprintf("status notAfter is %s but the issued certificate expires at %s",
+			expected.NotAfter.UTC().Format(time.RFC3339), leaf.NotAfter.UTC().Format(time.RFC3339)))
+	}
+
+	if expected.CommonName != "" && expected.CommonName != leaf.Subject.CommonName {
+		mismatches = append(mismatches, fmt.Sprintf("common name is %q but the issued certificate has %q",
+			expected.CommonName, leaf.Subject.CommonName))
+	}
+
+	ips := make([]string, 0, len(leaf.IPAddresses))
+	for _, ip := range leaf.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+
+	uris := make([]string, 0, len(leaf.URIs))
+	for _, uri := range leaf.URIs {
+		uris = append(uris, uri.String())
+	}
+
+	mismatches = appendSetMismatch(mismatches, "DNS names", expected.DNSNames, leaf.DNSNames)
+	mismatches = appendSetMismatch(mismatches, "IP addresses", normalizeIPs(expected.IPAddresses), ips)
+	mismatches = appendSetMismatch(mismatches, "URIs", expected.URIs, uris)
+	mismatches = appendSetMismatch(mismatches, "email addresses", expected.EmailAddresses, leaf.EmailAddresses)
+
+	return mismatches
+}
+
+// SerialNumber formats the serial number of a certificate as colon separated hex
+func SerialNumber(cert *x509.Certificate) string {
+	hex := fmt.Sprintf("%X", cert.SerialNumber)
+	if len(hex)%2 == 1 {
+		hex = "0" + hex
+	}
+
+	parts := make([]string, 0, len(hex)/2)
+	for i := 0; i < len(hex); i += 2 {
+		parts = append(parts, hex[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
+
+// appendSetMismatch compares two sets of values regardless of order
+func appendSetMismatch(mismatches []string, field string, expected, actual []string) []string {
+	missing := difference(expected, actual)
+	unexpected := difference(actual, expected)
+
+	if len(missing) > 0 {
+		mismatches = append(mismatches, fmt.Sprintf("%s missing from the issued certificate: %s", field, strings.Join(missing, ", ")))
+	}
+	if len(unexpected) > 0 {
+		mismatches = append(mismatches, fmt.Sprintf("issued certificate has unexpected %s: %s", field, strings.Join(unexpected, ", ")))
+	}
+	return mismatches
+}
+
+// difference returns the values in a that are not in b, sorted
+func difference(a, b []string) []string {
+	set := make(map[string]bool, len(b))
+	for _, value := range b {
+		set[value] = true
+	}
+
+	var diff []string
+	for _, value := range a {
+		if !set[value] {
+			diff = append(diff, value)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+// normalizeIPs formats IP addresses the way net.IP prints them
+func normalizeIPs(ips []string) []string {
+	normalized := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		if parsed := net.ParseIP(ip); parsed != nil {
+			ip = parsed.String()
+		}
+		normalized = append(normalized, ip)
+	}
+	return normalized
+}
+
+// Sources of chain elements within a TLS Secret
+const (
+	SourceTLSCrt = "tls.crt"
+	SourceCACrt  = "ca.crt"
+)
+
+// Roles of chain elements
+const (
+	RoleLeaf         = "leaf"
+	RoleIntermediate = "intermediate"
+	RoleRoot         = "root"
+)
+
+// ChainElement is a certificate in the chain stored in a TLS Secret
+type ChainElement struct {
+	Certificate *x509.Certificate
+	Source      string
+	Position    int
+	Role        string
+}
+
+// Chain builds the chain elements of a TLS Secret from the certificates in
+// tls.crt, the first of which is the leaf, and ca.crt
+func Chain(tlsCerts, caCerts []*x509.Certificate) []ChainElement {
+	chain := make([]ChainElement, 0, len(tlsCerts)+len(caCerts))
+
+	for i, cert := range tlsCerts {
+		role := RoleLeaf
+		if i > 0 {
+			role = caRole(cert)
+		}
+		chain = append(chain, ChainElement{Certificate: cert, Source: SourceTLSCrt, Position: i + 1, Role: role})
+	}
+
+	for i, cert := range caCerts {
+		chain = append(chain, ChainElement{Certificate: cert, Source: SourceCACrt, Position: i + 1, Role: caRole(cert)})
+	}
+
+	return chain
+}
+
+// Earliest returns the chain element that expires first. On ties the
+// element appearing first, i.e. the leaf, wins.
+func Earliest(chain []ChainElement) (ChainElement, bool) {
+	if len(chain) == 0 {
+		return ChainElement{}, false
+	}
+
+	earliest := chain[0]
+	for _, element := range chain[1:] {
+		if element.Certificate.NotAfter.Before(earliest.Certificate.NotAfter) {
+			earliest = element
+		}
+	}
+	return earliest, true
+}
+
+// Describe names the chain element, e.g. `intermediate "R3" (tls.crt #2)`
+func (e ChainElement) Describe() string {
+	return fmt.Sprintf("%s %q (%s #%d)", e.Role, e.Certificate.Subject.CommonName, e.Source, e.Position)
+}
+
+// caRole classifies a CA certificate as root if it is self-signed
+func caRole(cert *x509.Certificate) string {
+	if bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(cert) == nil {
+		return RoleRoot
+	}
+	return RoleIntermediate
+}
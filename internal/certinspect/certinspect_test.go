@@ -0,0 +1,138 @@
+package certinspect
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/certtest"
+)
+
+// newTestCertificate creates a self-signed certificate and returns it PEM encoded
+func newTestCertificate(t *testing.T, commonName string, dnsNames []string, notAfter time.Time) []byte {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(0x0abc),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.ParseIP("10.0.0.1")},
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	_, _, certPEM := certtest.Issue(t, template, nil, nil)
+	return certPEM
+}
+
+func TestParseCertificates(t *testing.T) {
+	notAfter := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+	data := append(newTestCertificate(t, "leaf", nil, notAfter), newTestCertificate(t, "intermediate", nil, notAfter)...)
+
+	certificates, err := ParseCertificates(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(certificates) != 2 || certificates[0].Subject.CommonName != "leaf" {
+		t.Errorf("Expected leaf followed by intermediate, got %d certificates", len(certificates))
+	}
+
+	if _, err := ParseCertificates([]byte("not a certificate")); err == nil {
+		t.Error("Expected error for invalid data, got nil")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	notAfter := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+	certificates, err := ParseCertificates(newTestCertificate(t, "example.com", []string{"example.com", "www.example.com"}, notAfter))
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	leaf := certificates[0]
+
+	matching := Expected{
+		CommonName:  "example.com",
+		DNSNames:    []string{"www.example.com", "example.com"},
+		IPAddresses: []string{"10.0.0.1"},
+		NotAfter:    &notAfter,
+	}
+	if mismatches := Compare(matching, leaf); len(mismatches) != 0 {
+		t.Errorf("Expected no mismatches, got %v", mismatches)
+	}
+
+	stale := notAfter.Add(-60 * 24 * time.Hour)
+	different := Expected{
+		CommonName:  "other.com",
+		DNSNames:    []string{"example.com", "api.example.com"},
+		IPAddresses: []string{"10.0.0.1"},
+		NotAfter:    &stale,
+	}
+	mismatches := Compare(different, leaf)
+	if len(mismatches) != 4 {
+		t.Errorf("Expected 4 mismatches (notAfter, common name, missing and unexpected DNS names), got %v", mismatches)
+	}
+}
+
+func TestSerialNumber(t *testing.T) {
+	certificates, err := ParseCertificates(newTestCertificate(t, "example.com", nil, time.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	if serial := SerialNumber(certificates[0]); serial != "0A:BC" {
+		t.Errorf("Expected serial '0A:BC', got '%s'", serial)
+	}
+}
+
+// issueCertificate creates a certificate signed by parent, or self-signed if parent is nil
+func issueCertificate(t *testing.T, commonName string, isCA bool, notAfter time.Time, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	cert, key, _ := certtest.Issue(t, template, parent, parentKey)
+	return cert, key
+}
+
+func TestChainAndEarliest(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	root, rootKey := issueCertificate(t, "Root CA", true, now.Add(3650*24*time.Hour), nil, nil)
+	intermediate, intermediateKey := issueCertificate(t, "Intermediate CA", true, now.Add(20*24*time.Hour), root, rootKey)
+	leaf, _ := issueCertificate(t, "example.com", false, now.Add(60*24*time.Hour), intermediate, intermediateKey)
+
+	chain := Chain([]*x509.Certificate{leaf, intermediate}, []*x509.Certificate{root})
+	if len(chain) != 3 {
+		t.Fatalf("Expected 3 chain elements, got %d", len(chain))
+	}
+
+	roles := []string{chain[0].Role, chain[1].Role, chain[2].Role}
+	if roles[0] != RoleLeaf || roles[1] != RoleIntermediate || roles[2] != RoleRoot {
+		t.Errorf("Expected leaf, intermediate and root roles, got %v", roles)
+	}
+
+	earliest, ok := Earliest(chain)
+	if !ok {
+		t.Fatal("Expected an earliest element")
+	}
+
+	if earliest.Describe() != `intermediate "Intermediate CA" (tls.crt #2)` {
+		t.Errorf("Expected the intermediate to expire first, got %s", earliest.Describe())
+	}
+
+	if _, ok := Earliest(nil); ok {
+		t.Error("Expected no earliest element for an empty chain")
+	}
+}
@@ -0,0 +1,148 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+// AllContexts selects every context of the kubeconfig
+const AllContexts = "*"
+
+// Cluster is a named Kubernetes cluster to monitor
+type Cluster struct {
+	Name   string
+	Config *rest.Config
+}
+
+// Load resolves the clusters to monitor: the kubeconfig files in
+// KubeconfigDir and the KubeconfigContexts of the kubeconfig. The cluster the
+// notifier runs in is monitored as ClusterName when no other clusters are
+// configured or ClusterName is set.
+func Load(cfg *config.Config) ([]Cluster, error) {
+	var clusters []Cluster
+
+	if cfg.KubeconfigDir != "" {
+		dirClusters, err := fromDir(cfg.KubeconfigDir)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, dirClusters...)
+	}
+
+	if len(cfg.KubeconfigContexts) > 0 {
+		contextClusters, err := fromContexts(clientcmd.NewDefaultClientConfigLoadingRules(), cfg.KubeconfigContexts)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, contextClusters...)
+	}
+
+	if len(clusters) == 0 || cfg.ClusterName != "" {
+		k8sConfig, err := Local()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Kubernetes config: %w", err)
+		}
+		clusters = append([]Cluster{{Name: cfg.ClusterName, Config: k8sConfig}}, clusters...)
+	}
+
+	seen := make(map[string]bool, len(clusters))
+	for _, cluster := range clusters {
+		if seen[cluster.Name] {
+			return nil, fmt.Errorf("duplicate cluster name %q", cluster.Name)
+		}
+		seen[cluster.Name] = true
+	}
+
+	return clusters, nil
+}
+
+// Local returns the in-cluster config, falling back to the current kubeconfig context
+func Local() (*rest.Config, error) {
+	// Try in-cluster config first
+	k8sConfig, err := rest.InClusterConfig()
+	if err == nil {
+		return k8sConfig, nil
+	}
+
+	// Fall back to kubeconfig
+	kubeconfig := os.Getenv("KUBECONFIG")
+	if kubeconfig == "" {
+		kubeconfig = os.Getenv("HOME") + "/.kube/config"
+	}
+
+	return clientcmd.BuildConfigFromFlags("", kubeconfig)
+}
+
+// fromDir loads each kubeconfig file in a directory as a cluster named after
+// the file, using the file's current context. Hidden files, such as the
+// bookkeeping entries of mounted Secrets, are skipped.
+func fromDir(dir string) ([]Cluster, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig directory: %w", err)
+	}
+
+	var clusters []Cluster
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat kubeconfig %s: %w", path, err)
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		k8sConfig, err := clientcmd.BuildConfigFromFlags("", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig %s: %w", path, err)
+		}
+		clusters = append(clusters, Cluster{Name: entry.Name(), Config: k8sConfig})
+	}
+
+	return clusters, nil
+}
+
+// fromContexts loads the named contexts of a kubeconfig as clusters named
+// after the context. AllContexts selects every context.
+func fromContexts(rules clientcmd.ClientConfigLoader, contexts []string) ([]Cluster, error) {
+	rawConfig, err := rules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	if len(contexts) == 1 && contexts[0] == AllContexts {
+		contexts = make([]string, 0, len(rawConfig.Contexts))
+		for name := range rawConfig.Contexts {
+			contexts = append(contexts, name)
+		}
+		sort.Strings(contexts)
+	}
+
+	clusters := make([]Cluster, 0, len(contexts))
+	for _, name := range contexts {
+		if _, ok := rawConfig.Contexts[name]; !ok {
+			return nil, fmt.Errorf("kubeconfig context %q not found", name)
+		}
+
+		k8sConfig, err := clientcmd.NewNonInteractiveClientConfig(*rawConfig, name, &clientcmd.ConfigOverrides{}, rules).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig context %q: %w", name, err)
+		}
+		clusters = append(clusters, Cluster{Name: name, Config: k8sConfig})
+	}
+
+	return clusters, nil
+}
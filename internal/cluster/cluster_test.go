@@ -0,0 +1,88 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// kubeconfig has two contexts pointing at different API servers
+const kubeconfig = `apiVersion: v1
+kind: Config
+current-context: staging
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com
+- name: staging
+  cluster:
+    server: https://staging.example.com
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+- name: staging
+  context:
+    cluster: staging
+    user: admin
+users:
+- name: admin
+  user:
+    token: secret
+`
+
+// writeKubeconfig writes the test kubeconfig to dir under name
+func writeKubeconfig(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
+func TestFromContexts(t *testing.T) {
+	path := writeKubeconfig(t, t.TempDir(), "config")
+	rules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: path}
+
+	clusters, err := fromContexts(rules, []string{AllContexts})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(clusters) != 2 || clusters[0].Name != "prod" || clusters[1].Name != "staging" {
+		t.Fatalf("Expected the prod and staging clusters, got %+v", clusters)
+	}
+	if clusters[0].Config.Host != "https://prod.example.com" {
+		t.Errorf("Expected the prod API server, got %s", clusters[0].Config.Host)
+	}
+
+	if _, err := fromContexts(rules, []string{"missing"}); err == nil {
+		t.Error("Expected an error for an unknown context, got nil")
+	}
+}
+
+func TestFromDir(t *testing.T) {
+	dir := t.TempDir()
+	writeKubeconfig(t, dir, "eu-west")
+	writeKubeconfig(t, dir, "..data")
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0o700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	clusters, err := fromDir(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(clusters) != 1 || clusters[0].Name != "eu-west" {
+		t.Fatalf("Expected only the eu-west cluster, got %+v", clusters)
+	}
+	if clusters[0].Config.Host != "https://staging.example.com" {
+		t.Errorf("Expected the current context's API server, got %s", clusters[0].Config.Host)
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -20,11 +21,104 @@ type Config struct {
 	// Kubernetes configuration
 	Namespace string `json:"namespace"`
 
+	// ClusterName tags notifications, metrics and API records of the cluster
+	// the notifier runs in or the current kubeconfig context points at
+	ClusterName string `json:"cluster_name"`
+
+	// KubeconfigContexts lists the kubeconfig contexts to monitor as separate
+	// clusters, "*" for all of them
+	KubeconfigContexts []string `json:"kubeconfig_contexts"`
+
+	// KubeconfigDir is a directory of kubeconfig files, such as mounted
+	// Secrets, each monitored as a cluster named after the file
+	KubeconfigDir string `json:"kubeconfig_dir"`
+
+	// InspectSecrets enables reading the TLS Secret of each Certificate
+	InspectSecrets bool `json:"inspect_secrets"`
+
+	// DiscoverSecrets enables checking TLS Secrets referenced by Ingresses
+	// and Gateways that are not managed by cert-manager
+	DiscoverSecrets bool `json:"discover_secrets"`
+
+	// ProbeEndpoints enables TLS handshakes with each Certificate's endpoints
+	// to check the certificate actually served. ProbeTimeout bounds probing
+	// the endpoints of a Certificate, at most ProbeConcurrency at a time.
+	ProbeEndpoints   bool          `json:"probe_endpoints"`
+	ProbeTimeout     time.Duration `json:"probe_timeout"`
+	ProbeConcurrency int           `json:"probe_concurrency"`
+
+	// ProbeAllowedEndpoints lists the hosts the probe-endpoints annotation
+	// may name besides the Certificate's own DNS names and IP addresses:
+	// host names, *.domain wildcards, IP addresses and CIDR ranges
+	ProbeAllowedEndpoints []string `json:"probe_allowed_endpoints"`
+
+	// PolicyFile is a YAML or JSON file of compliance rules certificates are checked against
+	PolicyFile string `json:"policy_file"`
+
+	// MonitorIssuers enables checking the Issuers and ClusterIssuers
+	// referenced by Certificates
+	MonitorIssuers bool `json:"monitor_issuers"`
+
+	// DiagnoseRenewals enables alerting on failing renewals with details from
+	// the CertificateRequests, Orders and Challenges involved
+	DiagnoseRenewals bool `json:"diagnose_renewals"`
+
+	// RecordEvents enables recording Kubernetes Events on Certificates when
+	// notifying about them
+	RecordEvents bool `json:"record_events"`
+
+	// AnnotateCertificates enables writing the last notification sent about a
+	// Certificate back to its annotations
+	AnnotateCertificates bool `json:"annotate_certificates"`
+
+	// NotificationPoliciesEnabled enables reading NotificationPolicy and
+	// ClusterNotificationPolicy resources that refine the notifications of
+	// the Certificates they select
+	NotificationPoliciesEnabled bool `json:"notification_policies_enabled"`
+
+	// ClusterResourceNamespace is where cert-manager reads ClusterIssuer secrets from
+	ClusterResourceNamespace string `json:"cluster_resource_namespace"`
+
+	// ShardingEnabled splits the monitored namespaces between replicas that
+	// coordinate through Leases in ShardNamespace
+	ShardingEnabled    bool          `json:"sharding_enabled"`
+	ShardID            string        `json:"shard_id"`
+	ShardNamespace     string        `json:"shard_namespace"`
+	ShardGroup         string        `json:"shard_group"`
+	ShardLeaseDuration time.Duration `json:"shard_lease_duration"`
+
+	// SilencesConfigMap names the ConfigMap in SilencesNamespace silences are
+	// persisted in, so that they survive restarts and are shared by the
+	// replicas. Without it silences are only kept in memory.
+	SilencesConfigMap string `json:"silences_configmap"`
+	SilencesNamespace string `json:"silences_namespace"`
+
+	// ConfigDirs are directories of files named after the environment
+	// variables they override, such as a mounted ConfigMap and Secret. They
+	// are watched for changes every ReloadInterval.
+	ConfigDirs     []string      `json:"config_dirs"`
+	ReloadInterval time.Duration `json:"reload_interval"`
+
+	// SecretFiles and SecretRefs are the files and namespace/name/key Secret
+	// references secret settings were read from, re-read on every reload
+	SecretFiles []string `json:"secret_files"`
+	SecretRefs  []string `json:"secret_refs"`
+
 	// Health check configuration
 	HealthPort int `json:"health_port"`
 
 	// Logging configuration
 	LogLevel string `json:"log_level"`
+
+	// Dry run configuration
+	DryRun       bool   `json:"dry_run"`
+	DryRunOutput string `json:"dry_run_output"`
+
+	// Dashboard configuration
+	DashboardEnabled  bool   `json:"dashboard_enabled"`
+	DashboardToken    string `json:"-"`
+	DashboardUsername string `json:"dashboard_username"`
+	DashboardPassword string `json:"-"`
 }
 
 // WebhookConfig holds webhook configuration
@@ -33,63 +127,209 @@ type WebhookConfig struct {
 	URL     string            `json:"url"`
 	Headers map[string]string `json:"headers"`
 	Timeout time.Duration     `json:"timeout"`
+	TLS     TLSConfig         `json:"tls"`
 }
 
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
+	return load(nil, true)
+}
+
+// LoadWithSecrets loads configuration like Load, reading the Secrets
+// referenced by *_SECRET_REF settings with secrets
+func LoadWithSecrets(secrets SecretReader) (*Config, error) {
+	return load(secrets, true)
+}
+
+// LoadWithoutWebhooks loads configuration for commands that never send
+// notifications, so WEBHOOK_URLS is not required
+func LoadWithoutWebhooks() (*Config, error) {
+	return load(nil, false)
+}
+
+// load loads configuration from environment variables and CONFIG_DIR,
+// optionally requiring webhooks
+func load(secrets SecretReader, requireWebhooks bool) (*Config, error) {
+	dirs := splitList(os.Getenv("CONFIG_DIR"))
+	getenv, err := lookupFromDirs(dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := loadFrom(getenv, secrets, requireWebhooks)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ConfigDirs = dirs
+	return cfg, nil
+}
+
+// loadFrom loads configuration from the variables returned by getenv,
+// optionally requiring webhooks
+func loadFrom(getenv func(string) string, secrets SecretReader, requireWebhooks bool) (*Config, error) {
 	cfg := &Config{
 		CheckInterval:       24 * time.Hour,      // Check daily
 		ExpirationThreshold: 30 * 24 * time.Hour, // 30 days
 		Namespace:           "",                  // All namespaces
 		HealthPort:          8080,
 		LogLevel:            "info",
+		DashboardEnabled:    true,
+		RecordEvents:        true,
+
+		ProbeTimeout:             5 * time.Second,
+		ProbeConcurrency:         5,
+		ClusterResourceNamespace: "cert-manager",
+
+		ShardNamespace:     "default",
+		ShardGroup:         "cert-manager-notifier",
+		ShardLeaseDuration: 30 * time.Second,
+
+		SilencesNamespace: "default",
+
+		ReloadInterval: 30 * time.Second,
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		cfg.ShardID = hostname
 	}
 
+	lookup := &secretLookup{getenv: getenv, secrets: secrets}
+	settings := &settingParser{getenv: getenv}
+
 	// Load webhook configurations
-	webhooks, err := loadWebhooks()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load webhooks: %w", err)
+	if requireWebhooks {
+		webhooks, err := loadWebhooks(getenv, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load webhooks: %w", err)
+		}
+		cfg.Webhooks = webhooks
 	}
-	cfg.Webhooks = webhooks
 
 	// Load optional configurations
-	if val := os.Getenv("CHECK_INTERVAL"); val != "" {
-		if duration, err := time.ParseDuration(val); err == nil {
-			cfg.CheckInterval = duration
-		}
+	settings.duration("CHECK_INTERVAL", &cfg.CheckInterval)
+
+	settings.duration("EXPIRATION_THRESHOLD", &cfg.ExpirationThreshold)
+
+	if val := getenv("NAMESPACE"); val != "" {
+		cfg.Namespace = val
 	}
 
-	if val := os.Getenv("EXPIRATION_THRESHOLD"); val != "" {
-		if duration, err := time.ParseDuration(val); err == nil {
-			cfg.ExpirationThreshold = duration
-		}
+	if val := getenv("CLUSTER_NAME"); val != "" {
+		cfg.ClusterName = val
 	}
 
-	if val := os.Getenv("NAMESPACE"); val != "" {
-		cfg.Namespace = val
+	if val := getenv("KUBECONFIG_CONTEXTS"); val != "" {
+		cfg.KubeconfigContexts = splitList(val)
 	}
 
-	if val := os.Getenv("HEALTH_PORT"); val != "" {
-		if port, err := strconv.Atoi(val); err == nil {
-			cfg.HealthPort = port
-		}
+	if val := getenv("KUBECONFIG_DIR"); val != "" {
+		cfg.KubeconfigDir = val
+	}
+
+	settings.boolean("INSPECT_SECRETS", &cfg.InspectSecrets)
+
+	settings.boolean("DISCOVER_SECRETS", &cfg.DiscoverSecrets)
+
+	settings.boolean("PROBE_ENDPOINTS", &cfg.ProbeEndpoints)
+
+	settings.duration("PROBE_TIMEOUT", &cfg.ProbeTimeout)
+
+	settings.integer("PROBE_CONCURRENCY", &cfg.ProbeConcurrency)
+
+	if val := getenv("PROBE_ALLOWED_ENDPOINTS"); val != "" {
+		cfg.ProbeAllowedEndpoints = splitList(val)
 	}
 
-	if val := os.Getenv("LOG_LEVEL"); val != "" {
+	if val := getenv("POLICY_FILE"); val != "" {
+		cfg.PolicyFile = val
+	}
+
+	settings.boolean("MONITOR_ISSUERS", &cfg.MonitorIssuers)
+
+	settings.boolean("DIAGNOSE_RENEWALS", &cfg.DiagnoseRenewals)
+
+	settings.boolean("RECORD_EVENTS", &cfg.RecordEvents)
+
+	settings.boolean("ANNOTATE_CERTIFICATES", &cfg.AnnotateCertificates)
+
+	settings.boolean("NOTIFICATION_POLICIES_ENABLED", &cfg.NotificationPoliciesEnabled)
+
+	if val := getenv("CLUSTER_RESOURCE_NAMESPACE"); val != "" {
+		cfg.ClusterResourceNamespace = val
+	}
+
+	settings.boolean("SHARDING_ENABLED", &cfg.ShardingEnabled)
+
+	if val := getenv("SHARD_ID"); val != "" {
+		cfg.ShardID = val
+	}
+
+	if val := getenv("SHARD_NAMESPACE"); val != "" {
+		cfg.ShardNamespace = val
+	}
+
+	if val := getenv("SHARD_GROUP"); val != "" {
+		cfg.ShardGroup = val
+	}
+
+	settings.duration("SHARD_LEASE_DURATION", &cfg.ShardLeaseDuration)
+
+	if val := getenv("SILENCES_CONFIGMAP"); val != "" {
+		cfg.SilencesConfigMap = val
+	}
+
+	if val := getenv("SILENCES_NAMESPACE"); val != "" {
+		cfg.SilencesNamespace = val
+	}
+
+	settings.duration("CONFIG_RELOAD_INTERVAL", &cfg.ReloadInterval)
+
+	settings.integer("HEALTH_PORT", &cfg.HealthPort)
+
+	if val := getenv("LOG_LEVEL"); val != "" {
 		cfg.LogLevel = val
 	}
 
+	settings.boolean("DRY_RUN", &cfg.DryRun)
+
+	cfg.DryRunOutput = getenv("DRY_RUN_OUTPUT")
+
+	settings.boolean("DASHBOARD_ENABLED", &cfg.DashboardEnabled)
+
+	cfg.DashboardUsername = getenv("DASHBOARD_USERNAME")
+
+	// Invalid values fail the load rather than falling back to the defaults,
+	// so that a typo in a reloaded configuration keeps the running one
+	if err := settings.err(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if cfg.DashboardToken, err = lookup.get("DASHBOARD_TOKEN"); err != nil {
+		return nil, err
+	}
+	if cfg.DashboardPassword, err = lookup.get("DASHBOARD_PASSWORD"); err != nil {
+		return nil, err
+	}
+
+	cfg.SecretFiles = lookup.files
+	cfg.SecretRefs = lookup.refs
+
 	return cfg, nil
 }
 
-// loadWebhooks loads webhook configurations from environment variables
-func loadWebhooks() ([]WebhookConfig, error) {
+// loadWebhooks loads webhook configurations from environment variables,
+// reading URLs and headers through lookup
+func loadWebhooks(getenv func(string) string, lookup *secretLookup) ([]WebhookConfig, error) {
 	var webhooks []WebhookConfig
 
 	// Support multiple webhooks via WEBHOOK_URLS (comma-separated)
-	urls := os.Getenv("WEBHOOK_URLS")
+	urls, err := lookup.get("WEBHOOK_URLS")
+	if err != nil {
+		return nil, err
+	}
 	if urls == "" {
-		return nil, fmt.Errorf("WEBHOOK_URLS environment variable is required")
+		return nil, fmt.Errorf("WEBHOOK_URLS, WEBHOOK_URLS_FILE or WEBHOOK_URLS_SECRET_REF is required")
 	}
 
 	urlList := strings.Split(urls, ",")
@@ -107,8 +347,11 @@ func loadWebhooks() ([]WebhookConfig, error) {
 		}
 
 		// Load headers for this webhook
-		headersKey := fmt.Sprintf("WEBHOOK_%d_HEADERS", i+1)
-		if headers := os.Getenv(headersKey); headers != "" {
+		headers, err := lookup.get(fmt.Sprintf("WEBHOOK_%d_HEADERS", i+1))
+		if err != nil {
+			return nil, err
+		}
+		if headers != "" {
 			headerPairs := strings.Split(headers, ",")
 			for _, pair := range headerPairs {
 				if kv := strings.SplitN(pair, ":", 2); len(kv) == 2 {
@@ -118,12 +361,25 @@ func loadWebhooks() ([]WebhookConfig, error) {
 		}
 
 		// Load timeout for this webhook
-		timeoutKey := fmt.Sprintf("WEBHOOK_%d_TIMEOUT", i+1)
-		if timeout := os.Getenv(timeoutKey); timeout != "" {
-			if duration, err := time.ParseDuration(timeout); err == nil {
-				webhook.Timeout = duration
+		settings := &settingParser{getenv: getenv}
+		settings.duration(fmt.Sprintf("WEBHOOK_%d_TIMEOUT", i+1), &webhook.Timeout)
+		if err := settings.err(); err != nil {
+			return nil, err
+		}
+
+		// Load TLS settings for this webhook. The PEM values can be read
+		// from files or Secrets like the other secret settings.
+		prefix := fmt.Sprintf("WEBHOOK_%d_TLS_", i+1)
+		for _, setting := range []struct {
+			key   string
+			value *string
+		}{{"CA", &webhook.TLS.CA}, {"CERT", &webhook.TLS.Cert}, {"KEY", &webhook.TLS.Key}} {
+			if *setting.value, err = lookup.get(prefix + setting.key); err != nil {
+				return nil, err
 			}
 		}
+		webhook.TLS.ServerName = getenv(prefix + "SERVER_NAME")
+		webhook.TLS.MinVersion = getenv(prefix + "MIN_VERSION")
 
 		webhooks = append(webhooks, webhook)
 	}
@@ -134,3 +390,63 @@ func loadWebhooks() ([]WebhookConfig, error) {
 
 	return webhooks, nil
 }
+
+// settingParser parses typed settings, collecting an error for each
+// setting with an invalid value
+type settingParser struct {
+	getenv func(string) string
+	errs   []error
+}
+
+// duration sets dst to the duration in key, if it is set
+func (p *settingParser) duration(key string, dst *time.Duration) {
+	if val := p.getenv(key); val != "" {
+		duration, err := ParseDuration(val)
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("invalid %s %q: %w", key, val, err))
+			return
+		}
+		*dst = duration
+	}
+}
+
+// boolean sets dst to the boolean in key, if it is set
+func (p *settingParser) boolean(key string, dst *bool) {
+	if val := p.getenv(key); val != "" {
+		value, err := strconv.ParseBool(val)
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("invalid %s %q: %w", key, val, err))
+			return
+		}
+		*dst = value
+	}
+}
+
+// integer sets dst to the integer in key, if it is set
+func (p *settingParser) integer(key string, dst *int) {
+	if val := p.getenv(key); val != "" {
+		value, err := strconv.Atoi(val)
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("invalid %s %q: %w", key, val, err))
+			return
+		}
+		*dst = value
+	}
+}
+
+// err returns the errors of all invalid settings
+func (p *settingParser) err() error {
+	return errors.Join(p.errs...)
+}
+
+// ParseDuration parses a duration, additionally accepting a number of days such as "7d"
+func ParseDuration(val string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(val, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", val)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(val)
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -70,3 +72,343 @@ func TestLoad_NoWebhooks(t *testing.T) {
 		t.Error("Expected error when no webhooks configured, got nil")
 	}
 }
+
+func TestLoad_Dashboard(t *testing.T) {
+	os.Setenv("WEBHOOK_URLS", "https://example.com/webhook")
+	os.Setenv("DASHBOARD_ENABLED", "false")
+	os.Setenv("DASHBOARD_TOKEN", "secret-token")
+
+	defer func() {
+		os.Unsetenv("WEBHOOK_URLS")
+		os.Unsetenv("DASHBOARD_ENABLED")
+		os.Unsetenv("DASHBOARD_TOKEN")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if cfg.DashboardEnabled {
+		t.Error("Expected dashboard to be disabled")
+	}
+
+	if cfg.DashboardToken != "secret-token" {
+		t.Errorf("Expected dashboard token 'secret-token', got '%s'", cfg.DashboardToken)
+	}
+}
+
+func TestLoad_Probe(t *testing.T) {
+	os.Setenv("WEBHOOK_URLS", "https://example.com/webhook")
+	os.Setenv("PROBE_CONCURRENCY", "3")
+	os.Setenv("PROBE_ALLOWED_ENDPOINTS", "10.0.0.0/8, *.internal")
+
+	defer func() {
+		os.Unsetenv("WEBHOOK_URLS")
+		os.Unsetenv("PROBE_CONCURRENCY")
+		os.Unsetenv("PROBE_ALLOWED_ENDPOINTS")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if cfg.ProbeConcurrency != 3 || len(cfg.ProbeAllowedEndpoints) != 2 || cfg.ProbeAllowedEndpoints[1] != "*.internal" {
+		t.Errorf("Unexpected probe settings: %d %v", cfg.ProbeConcurrency, cfg.ProbeAllowedEndpoints)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid configuration, got: %v", err)
+	}
+
+	cfg.ProbeAllowedEndpoints = []string{"10.0.0.0/33"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "invalid probe allowed endpoints") {
+		t.Errorf("Expected an error for an invalid CIDR range, got: %v", err)
+	}
+
+	cfg.ProbeAllowedEndpoints = nil
+	cfg.ProbeConcurrency = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "probe concurrency must be positive") {
+		t.Errorf("Expected an error for a non-positive concurrency, got: %v", err)
+	}
+
+	cfg.ProbeConcurrency = 5
+	cfg.ProbeTimeout = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "probe timeout must be positive") {
+		t.Errorf("Expected an error for a non-positive timeout, got: %v", err)
+	}
+}
+
+func TestLoad_Sharding(t *testing.T) {
+	os.Setenv("WEBHOOK_URLS", "https://example.com/webhook")
+	os.Setenv("SHARDING_ENABLED", "true")
+	os.Setenv("SHARD_LEASE_DURATION", "0s")
+
+	defer func() {
+		os.Unsetenv("WEBHOOK_URLS")
+		os.Unsetenv("SHARDING_ENABLED")
+		os.Unsetenv("SHARD_LEASE_DURATION")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "shard lease duration must be at least 1s") {
+		t.Errorf("Expected an error for a zero lease duration, got: %v", err)
+	}
+
+	cfg.ShardLeaseDuration = 30 * time.Second
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "a silences ConfigMap is required when sharding is enabled") {
+		t.Errorf("Expected an error for sharding without shared silences, got: %v", err)
+	}
+
+	cfg.SilencesConfigMap = "silences"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	cfg.ShardingEnabled = false
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected the lease duration to be ignored without sharding, got: %v", err)
+	}
+}
+
+func TestLoad_ReloadInterval(t *testing.T) {
+	os.Setenv("WEBHOOK_URLS", "https://example.com/webhook")
+	os.Setenv("CONFIG_RELOAD_INTERVAL", "0s")
+
+	defer func() {
+		os.Unsetenv("WEBHOOK_URLS")
+		os.Unsetenv("CONFIG_RELOAD_INTERVAL")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "config reload interval must be positive") {
+		t.Errorf("Expected an error for a zero reload interval, got: %v", err)
+	}
+}
+
+func TestLoad_InvalidSettings(t *testing.T) {
+	tests := map[string]string{
+		"CHECK_INTERVAL":    "5mm",
+		"PROBE_TIMEOUT":     "abc",
+		"DRY_RUN":           "yes",
+		"HEALTH_PORT":       "http",
+		"WEBHOOK_1_TIMEOUT": "10",
+	}
+
+	for key, val := range tests {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv("WEBHOOK_URLS", "https://example.com/webhook")
+			t.Setenv(key, val)
+
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), "invalid "+key) {
+				t.Errorf("Expected an error for %s=%s, got: %v", key, val, err)
+			}
+		})
+	}
+
+	// The days accepted by the other durations are accepted here as well
+	t.Setenv("WEBHOOK_URLS", "https://example.com/webhook")
+	t.Setenv("EXPIRATION_THRESHOLD", "14d")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if cfg.ExpirationThreshold != 14*24*time.Hour {
+		t.Errorf("Expected an expiration threshold of 14 days, got %v", cfg.ExpirationThreshold)
+	}
+}
+
+func TestLoadWithoutWebhooks(t *testing.T) {
+	os.Unsetenv("WEBHOOK_URLS")
+
+	cfg, err := LoadWithoutWebhooks()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(cfg.Webhooks) != 0 {
+		t.Errorf("Expected no webhooks, got %d", len(cfg.Webhooks))
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"72h": 72 * time.Hour,
+		"30m": 30 * time.Minute,
+	}
+
+	for val, expected := range tests {
+		duration, err := ParseDuration(val)
+		if err != nil {
+			t.Errorf("%s: expected no error, got: %v", val, err)
+			continue
+		}
+		if duration != expected {
+			t.Errorf("%s: expected %v, got %v", val, expected, duration)
+		}
+	}
+
+	if _, err := ParseDuration("xd"); err == nil {
+		t.Error("Expected error for invalid days, got nil")
+	}
+}
+
+func TestLoad_ConfigDir(t *testing.T) {
+	configMap := t.TempDir()
+	secret := t.TempDir()
+	files := map[string]string{
+		filepath.Join(configMap, "CHECK_INTERVAL"): "2h\n",
+		filepath.Join(configMap, "WEBHOOK_URLS"):   "https://example.com/from-configmap",
+		filepath.Join(configMap, "..data"):         "ignored",
+		filepath.Join(secret, "WEBHOOK_URLS"):      "https://example.com/from-secret",
+		filepath.Join(secret, "WEBHOOK_1_HEADERS"): "Authorization:Bearer token",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	t.Setenv("CONFIG_DIR", configMap+","+secret)
+	t.Setenv("WEBHOOK_URLS", "https://example.com/from-env")
+	t.Setenv("NAMESPACE", "from-env")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if cfg.CheckInterval != 2*time.Hour {
+		t.Errorf("Expected the check interval from the config directory, got %s", cfg.CheckInterval)
+	}
+	if len(cfg.Webhooks) != 1 || cfg.Webhooks[0].URL != "https://example.com/from-secret" {
+		t.Errorf("Expected the webhook from the last config directory, got %+v", cfg.Webhooks)
+	}
+	if cfg.Webhooks[0].Headers["Authorization"] != "Bearer token" {
+		t.Errorf("Expected the header from the secret, got %v", cfg.Webhooks[0].Headers)
+	}
+	if cfg.Namespace != "from-env" {
+		t.Errorf("Expected settings without a file to come from the environment, got %q", cfg.Namespace)
+	}
+
+	before, err := Fingerprint(cfg.ConfigDirs, cfg.SecretFiles)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configMap, "CHECK_INTERVAL"), []byte("3h"), 0o600); err != nil {
+		t.Fatalf("Failed to update file: %v", err)
+	}
+	after, err := Fingerprint(cfg.ConfigDirs, cfg.SecretFiles)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if before == after {
+		t.Error("Expected the fingerprint to change with the files")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := &Config{
+		CheckInterval: time.Hour,
+		Webhooks: []WebhookConfig{{
+			Name:    "webhook-1",
+			URL:     "https://hooks.slack.com/services/T000/B000/XXXX",
+			Headers: map[string]string{"Authorization": "Bearer old"},
+			Timeout: 30 * time.Second,
+		}},
+		DashboardEnabled: true,
+		DashboardToken:   "old-token",
+	}
+	new := &Config{
+		CheckInterval: 2 * time.Hour,
+		Webhooks: []WebhookConfig{{
+			Name:    "webhook-1",
+			URL:     "https://hooks.slack.com/services/T000/B000/YYYY",
+			Headers: map[string]string{"Authorization": "Bearer new"},
+			Timeout: 30 * time.Second,
+		}},
+		DashboardToken: "new-token",
+	}
+
+	changes := strings.Join(Diff(old, new), "\n")
+	for _, expected := range []string{"check_interval: 1h0m0s -> 2h0m0s", "webhooks:", "DashboardToken changed"} {
+		if !strings.Contains(changes, expected) {
+			t.Errorf("Expected %q in the diff, got:\n%s", expected, changes)
+		}
+	}
+	for _, secret := range []string{"XXXX", "YYYY", "Bearer", "token"} {
+		if strings.Contains(changes, secret) {
+			t.Errorf("Expected %q to be redacted from the diff, got:\n%s", secret, changes)
+		}
+	}
+
+	// Dashboard credentials can be rotated, but the dashboard stays as it started
+	if kept := new.KeepStartupSettings(old); len(kept) != 1 || kept[0] != "DashboardEnabled" || new.DashboardToken != "new-token" {
+		t.Errorf("Expected only the dashboard to be kept until restart, got %v", kept)
+	}
+}
+
+func TestDiff_SecretValues(t *testing.T) {
+	old := &Config{Webhooks: []WebhookConfig{{Name: "webhook-1", URL: "https://hooks.slack.com/services/T000/B000/XXXX"}}}
+	new := &Config{Webhooks: []WebhookConfig{{Name: "webhook-1", URL: "https://hooks.slack.com/services/T000/B000/YYYY"}}}
+
+	changes := Diff(old, new)
+	if len(changes) != 1 || changes[0] != "webhooks: secret values changed" {
+		t.Errorf("Expected a rotated URL to be reported without its value, got %v", changes)
+	}
+}
+
+func TestLoad_WebhookTLS(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	t.Setenv("WEBHOOK_URLS", "https://internal.example.com/webhook")
+	t.Setenv("WEBHOOK_1_TLS_CA_FILE", caFile)
+	t.Setenv("WEBHOOK_1_TLS_SERVER_NAME", "receiver.internal")
+	t.Setenv("WEBHOOK_1_TLS_MIN_VERSION", "1.3")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	settings := cfg.Webhooks[0].TLS
+	if settings.CA != "not a certificate" || settings.ServerName != "receiver.internal" || settings.MinVersion != "1.3" {
+		t.Errorf("Unexpected TLS settings: %+v", settings)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "no certificates found in the CA bundle") {
+		t.Errorf("Expected an invalid CA bundle to be rejected, got: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		settings TLSConfig
+		err      string
+	}{
+		{"plain http", "http://internal.example.com/webhook", TLSConfig{ServerName: "receiver.internal"}, "no https URL"},
+		{"unknown version", "https://internal.example.com/webhook", TLSConfig{MinVersion: "1.4"}, "unsupported minimum TLS version"},
+		{"certificate without key", "https://internal.example.com/webhook", TLSConfig{Cert: "cert"}, "requires both a certificate and a key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Webhooks[0].URL = tt.url
+			cfg.Webhooks[0].TLS = tt.settings
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/probe"
+)
+
+// Validate checks a configuration for values that cannot work
+func (c *Config) Validate() error {
+	if c.CheckInterval <= 0 {
+		return fmt.Errorf("check interval must be positive, got %s", c.CheckInterval)
+	}
+	if c.ExpirationThreshold <= 0 {
+		return fmt.Errorf("expiration threshold must be positive, got %s", c.ExpirationThreshold)
+	}
+	if c.ProbeTimeout <= 0 {
+		return fmt.Errorf("probe timeout must be positive, got %s", c.ProbeTimeout)
+	}
+	if c.ProbeConcurrency <= 0 {
+		return fmt.Errorf("probe concurrency must be positive, got %d", c.ProbeConcurrency)
+	}
+	if _, err := probe.NewAllowlist(c.ProbeAllowedEndpoints); err != nil {
+		return fmt.Errorf("invalid probe allowed endpoints: %w", err)
+	}
+	if c.ReloadInterval <= 0 {
+		return fmt.Errorf("config reload interval must be positive, got %s", c.ReloadInterval)
+	}
+	// Leases are kept in whole seconds and renewed every third of the duration
+	if c.ShardingEnabled && c.ShardLeaseDuration < time.Second {
+		return fmt.Errorf("shard lease duration must be at least 1s, got %s", c.ShardLeaseDuration)
+	}
+	// A silence created through any replica must reach the replica notifying
+	if c.ShardingEnabled && c.SilencesConfigMap == "" {
+		return fmt.Errorf("a silences ConfigMap is required when sharding is enabled")
+	}
+
+	for _, webhook := range c.Webhooks {
+		u, err := url.Parse(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook %s has an invalid URL %s", webhook.Name, RedactURL(webhook.URL))
+		}
+		if webhook.Timeout <= 0 {
+			return fmt.Errorf("webhook %s timeout must be positive, got %s", webhook.Name, webhook.Timeout)
+		}
+		if webhook.TLS.Enabled() {
+			if u.Scheme != "https" {
+				return fmt.Errorf("webhook %s has TLS settings but no https URL", webhook.Name)
+			}
+			if _, err := webhook.TLS.ClientConfig(); err != nil {
+				return fmt.Errorf("webhook %s has invalid TLS settings: %w", webhook.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// startupFields are the settings only read on startup, such as those
+// creating clients, servers and background workers
+var startupFields = []string{
+	"ClusterName", "KubeconfigContexts", "KubeconfigDir", "RecordEvents",
+	"ShardingEnabled", "ShardID", "ShardNamespace", "ShardGroup", "ShardLeaseDuration",
+	"SilencesConfigMap", "SilencesNamespace",
+	"ConfigDirs", "ReloadInterval", "HealthPort", "LogLevel", "DryRun", "DryRunOutput",
+	"DashboardEnabled",
+}
+
+// KeepStartupSettings copies the settings that only take effect on startup
+// from the running configuration and returns the names of those that differed
+func (c *Config) KeepStartupSettings(running *Config) []string {
+	target := reflect.ValueOf(c).Elem()
+	source := reflect.ValueOf(running).Elem()
+
+	var kept []string
+	for _, name := range startupFields {
+		field := target.FieldByName(name)
+		runningField := source.FieldByName(name)
+		if !reflect.DeepEqual(field.Interface(), runningField.Interface()) {
+			kept = append(kept, name)
+			field.Set(runningField)
+		}
+	}
+	return kept
+}
+
+// Diff describes the settings that differ between two configurations, e.g.
+// "check_interval: 24h0m0s -> 12h0m0s". Webhook URLs and sensitive headers
+// are redacted, and secret settings are only reported as changed.
+func Diff(old, new *Config) []string {
+	oldValue := reflect.ValueOf(*old)
+	newValue := reflect.ValueOf(*new)
+	fields := oldValue.Type()
+
+	var changes []string
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		oldField := oldValue.Field(i).Interface()
+		newField := newValue.Field(i).Interface()
+		if reflect.DeepEqual(oldField, newField) {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			changes = append(changes, fmt.Sprintf("%s changed", field.Name))
+			continue
+		}
+
+		if webhooks, ok := oldField.([]WebhookConfig); ok {
+			oldField = describeWebhooks(webhooks)
+			newField = describeWebhooks(newField.([]WebhookConfig))
+			if reflect.DeepEqual(oldField, newField) {
+				changes = append(changes, fmt.Sprintf("%s: secret values changed", name))
+				continue
+			}
+		}
+		changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, oldField, newField))
+	}
+
+	return changes
+}
+
+// describeWebhooks renders webhooks for logs with their secrets redacted
+func describeWebhooks(webhooks []WebhookConfig) []string {
+	described := make([]string, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		headers := RedactHeaders(webhook.Headers)
+		names := make([]string, 0, len(headers))
+		for name := range headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		pairs := make([]string, 0, len(names))
+		for _, name := range names {
+			pairs = append(pairs, name+":"+headers[name])
+		}
+		described = append(described, fmt.Sprintf("%s=%s{timeout=%s headers=[%s]%s}", webhook.Name, RedactURL(webhook.URL), webhook.Timeout, strings.Join(pairs, ","), describeTLS(webhook.TLS)))
+	}
+	return described
+}
+
+// describeTLS renders the TLS settings of a webhook for logs, naming the
+// PEM values that are set without showing them
+func describeTLS(settings TLSConfig) string {
+	if !settings.Enabled() {
+		return ""
+	}
+
+	var parts []string
+	for _, pem := range []struct{ name, value string }{{"ca", settings.CA}, {"cert", settings.Cert}, {"key", settings.Key}} {
+		if pem.value != "" {
+			parts = append(parts, pem.name+"="+Redacted)
+		}
+	}
+	if settings.ServerName != "" {
+		parts = append(parts, "server_name="+settings.ServerName)
+	}
+	if settings.MinVersion != "" {
+		parts = append(parts, "min_version="+settings.MinVersion)
+	}
+	return " tls=[" + strings.Join(parts, ",") + "]"
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(val string) []string {
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// readDir reads the files of a config directory keyed by file name. Hidden
+// files, such as the bookkeeping entries of mounted ConfigMaps and Secrets,
+// and directories are skipped.
+func readDir(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory: %w", err)
+	}
+
+	files := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat config file %s: %w", path, err)
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		files[entry.Name()] = strings.TrimSpace(string(data))
+	}
+
+	return files, nil
+}
+
+// lookupFromDirs returns a lookup of environment variables overridden by
+// the files in dirs. Later directories take precedence.
+func lookupFromDirs(dirs []string) (func(string) string, error) {
+	overrides := make(map[string]string)
+	for _, dir := range dirs {
+		files, err := readDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range files {
+			overrides[name] = value
+		}
+	}
+
+	return func(key string) string {
+		if value, ok := overrides[key]; ok {
+			return value
+		}
+		return os.Getenv(key)
+	}, nil
+}
+
+// Fingerprint hashes the contents of the config directories and files, so
+// that changes can be detected by polling
+func Fingerprint(dirs, files []string) (string, error) {
+	hash := sha256.New()
+	for _, dir := range dirs {
+		contents, err := readDir(dir)
+		if err != nil {
+			return "", err
+		}
+
+		names := make([]string, 0, len(contents))
+		for name := range contents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintf(hash, "%s\x00", dir)
+		for _, name := range names {
+			fmt.Fprintf(hash, "%s\x00%s\x00", name, contents[name])
+		}
+	}
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		fmt.Fprintf(hash, "%s\x00%s\x00", path, data)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
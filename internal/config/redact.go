@@ -0,0 +1,65 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces secret values in logs and config dumps
+const Redacted = "REDACTED"
+
+// sensitiveHeaderParts mark header names whose values are treated as secrets
+var sensitiveHeaderParts = []string{"auth", "token", "secret", "key", "password", "signature", "cookie"}
+
+// IsSensitiveHeader reports whether a header value should be redacted
+func IsSensitiveHeader(name string) bool {
+	lower := strings.ToLower(name)
+	for _, part := range sensitiveHeaderParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeaders returns a copy of the headers with sensitive values redacted
+func RedactHeaders(headers map[string]string) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for key, value := range headers {
+		if IsSensitiveHeader(key) {
+			value = Redacted
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// RedactURL hides credentials that webhook URLs commonly carry: user info,
+// query values and every path segment after the first one, as used by
+// Slack, Discord and Teams webhook URLs.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Redacted
+	}
+
+	if u.User != nil {
+		u.User = url.User(Redacted)
+	}
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		for key := range query {
+			query.Set(key, Redacted)
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	segments := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(segments) > 1 {
+		u.Path = "/" + segments[0] + "/" + Redacted
+		u.RawPath = ""
+	}
+
+	return u.String()
+}
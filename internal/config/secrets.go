@@ -0,0 +1,100 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// secretReadTimeout bounds reading a Secret referenced by the configuration
+const secretReadTimeout = 10 * time.Second
+
+// SecretReader reads the value of a key of a Kubernetes Secret
+type SecretReader func(ctx context.Context, namespace, name, key string) (string, error)
+
+// KubernetesSecrets returns a SecretReader reading Secrets with client
+func KubernetesSecrets(client kubernetes.Interface) SecretReader {
+	return func(ctx context.Context, namespace, name, key string) (string, error) {
+		secret, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+		}
+		value, ok := secret.Data[key]
+		if !ok {
+			return "", fmt.Errorf("secret %s/%s has no key %s", namespace, name, key)
+		}
+		return string(value), nil
+	}
+}
+
+// secretLookup resolves secret settings such as WEBHOOK_URLS, which can be
+// set directly, read from the file named by WEBHOOK_URLS_FILE or from the
+// Secret key referenced by WEBHOOK_URLS_SECRET_REF as namespace/name/key.
+// It records the files and Secrets it read so they can be watched.
+type secretLookup struct {
+	getenv  func(string) string
+	secrets SecretReader
+	files   []string
+	refs    []string
+}
+
+// get returns the value of a secret setting
+func (l *secretLookup) get(key string) (string, error) {
+	value := l.getenv(key)
+	path := l.getenv(key + "_FILE")
+	ref := l.getenv(key + "_SECRET_REF")
+
+	set := 0
+	for _, source := range []string{value, path, ref} {
+		if source != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return "", fmt.Errorf("only one of %s, %s_FILE and %s_SECRET_REF can be set", key, key, key)
+	}
+
+	switch {
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s_FILE: %w", key, err)
+		}
+		l.files = append(l.files, path)
+		return strings.TrimSpace(string(data)), nil
+	case ref != "":
+		namespace, name, secretKey, err := parseSecretRef(ref)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s_SECRET_REF: %w", key, err)
+		}
+		if l.secrets == nil {
+			return "", fmt.Errorf("%s_SECRET_REF requires access to Kubernetes Secrets", key)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), secretReadTimeout)
+		defer cancel()
+
+		data, err := l.secrets(ctx, namespace, name, secretKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s_SECRET_REF: %w", key, err)
+		}
+		l.refs = append(l.refs, ref)
+		return strings.TrimSpace(data), nil
+	default:
+		return value, nil
+	}
+}
+
+// parseSecretRef splits a namespace/name/key Secret reference
+func parseSecretRef(ref string) (namespace, name, key string, err error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", fmt.Errorf("expected namespace/name/key, got %q", ref)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLoadWithSecrets(t *testing.T) {
+	dir := t.TempDir()
+	urlsFile := filepath.Join(dir, "urls")
+	if err := os.WriteFile(urlsFile, []byte("https://hooks.slack.com/services/T000/B000/XXXX\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	client := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "notifier"},
+		Data: map[string][]byte{
+			"headers": []byte("Authorization:Bearer secret"),
+			"token":   []byte("dashboard-token"),
+		},
+	})
+
+	t.Setenv("WEBHOOK_URLS_FILE", urlsFile)
+	t.Setenv("WEBHOOK_1_HEADERS_SECRET_REF", "monitoring/notifier/headers")
+	t.Setenv("DASHBOARD_TOKEN_SECRET_REF", "monitoring/notifier/token")
+
+	cfg, err := LoadWithSecrets(KubernetesSecrets(client))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(cfg.Webhooks) != 1 || cfg.Webhooks[0].URL != "https://hooks.slack.com/services/T000/B000/XXXX" {
+		t.Errorf("Expected the webhook URL from the file, got %+v", cfg.Webhooks)
+	}
+	if cfg.Webhooks[0].Headers["Authorization"] != "Bearer secret" {
+		t.Errorf("Expected the headers from the secret, got %v", cfg.Webhooks[0].Headers)
+	}
+	if cfg.DashboardToken != "dashboard-token" {
+		t.Errorf("Expected the dashboard token from the secret, got %q", cfg.DashboardToken)
+	}
+	if len(cfg.SecretFiles) != 1 || cfg.SecretFiles[0] != urlsFile {
+		t.Errorf("Expected the secret file to be recorded, got %v", cfg.SecretFiles)
+	}
+	if len(cfg.SecretRefs) != 2 {
+		t.Errorf("Expected both secret references to be recorded, got %v", cfg.SecretRefs)
+	}
+
+	// Without access to Secrets the references cannot be resolved
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "WEBHOOK_1_HEADERS_SECRET_REF") {
+		t.Errorf("Expected an error about the secret reference, got: %v", err)
+	}
+}
+
+func TestLoadWithSecrets_Errors(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		err  string
+	}{
+		{
+			name: "value and file",
+			env:  map[string]string{"WEBHOOK_URLS": "https://example.com/webhook", "WEBHOOK_URLS_FILE": "/nonexistent"},
+			err:  "only one of WEBHOOK_URLS",
+		},
+		{
+			name: "missing file",
+			env:  map[string]string{"WEBHOOK_URLS_FILE": "/nonexistent"},
+			err:  "failed to read WEBHOOK_URLS_FILE",
+		},
+		{
+			name: "invalid reference",
+			env:  map[string]string{"WEBHOOK_URLS_SECRET_REF": "notifier/urls"},
+			err:  "invalid WEBHOOK_URLS_SECRET_REF",
+		},
+		{
+			name: "missing secret",
+			env:  map[string]string{"WEBHOOK_URLS_SECRET_REF": "monitoring/notifier/urls"},
+			err:  "failed to read WEBHOOK_URLS_SECRET_REF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := LoadWithSecrets(KubernetesSecrets(client))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got: %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+)
+
+// tlsVersions maps the accepted minimum TLS versions to their constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig holds the TLS settings used to connect to a webhook. CA, Cert
+// and Key are PEM encoded; the system roots are trusted when CA is empty.
+type TLSConfig struct {
+	CA         string `json:"ca"`
+	Cert       string `json:"cert"`
+	Key        string `json:"-"`
+	ServerName string `json:"server_name"`
+	MinVersion string `json:"min_version"`
+}
+
+// Enabled reports whether any TLS setting differs from the defaults
+func (t TLSConfig) Enabled() bool {
+	return t != TLSConfig{}
+}
+
+// Fingerprint identifies the settings, so clients built from them can be
+// reused until they change
+func (t TLSConfig) Fingerprint() string {
+	hash := sha256.New()
+	for _, value := range []string{t.CA, t.Cert, t.Key, t.ServerName, t.MinVersion} {
+		fmt.Fprintf(hash, "%s\x00", value)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ClientConfig builds the TLS client configuration for the settings
+func (t TLSConfig) ClientConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName: t.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if t.MinVersion != "" {
+		version, ok := tlsVersions[t.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported minimum TLS version %q, expected 1.0, 1.1, 1.2 or 1.3", t.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if t.CA != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(t.CA)) {
+			return nil, fmt.Errorf("no certificates found in the CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (t.Cert == "") != (t.Key == "") {
+		return nil, fmt.Errorf("a client certificate requires both a certificate and a key")
+	}
+	if t.Cert != "" {
+		certificate, err := tls.X509KeyPair([]byte(t.Cert), []byte(t.Key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
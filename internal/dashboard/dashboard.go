@@ -0,0 +1,30 @@
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/api"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// Register serves the dashboard under /dashboard/ on the router.
+// The assets contain no certificate data, so with token auth they are served
+// openly and the dashboard sends the token with its API requests. With basic
+// auth the assets are protected too so that the browser prompts for credentials.
+func Register(router api.Router, auth *api.Credentials) error {
+	assets, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		return err
+	}
+
+	fileServer := http.StripPrefix("/dashboard/", http.FileServerFS(assets))
+	router.HandleFunc("GET /dashboard/", auth.WrapBasic(fileServer.ServeHTTP))
+	router.HandleFunc("GET /dashboard", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/dashboard/", http.StatusMovedPermanently)
+	})
+	return nil
+}
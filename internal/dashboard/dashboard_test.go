@@ -0,0 +1,58 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/api"
+)
+
+func TestRegister(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := Register(mux, api.NewCredentials(api.Auth{})); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dashboard/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	if !strings.Contains(rec.Body.String(), "Certificate inventory") {
+		t.Error("Expected dashboard page to be served")
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dashboard/app.js", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for app.js, got %d", rec.Code)
+	}
+}
+
+func TestRegister_BasicAuth(t *testing.T) {
+	mux := http.NewServeMux()
+	if err := Register(mux, api.NewCredentials(api.Auth{Username: "admin", Password: "secret"})); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dashboard/", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard/", nil)
+	req.SetBasicAuth("admin", "secret")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 with credentials, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,203 @@
+(function () {
+  "use strict";
+
+  var tokenKey = "cert-manager-notifier-token";
+  var state = {
+    certificates: [],
+    notifications: [],
+    sortKey: "days",
+    sortAsc: true
+  };
+
+  // fetchJSON requests an API endpoint, asking for a bearer token when required
+  function fetchJSON(url) {
+    var headers = {};
+    var token = sessionStorage.getItem(tokenKey);
+    if (token) {
+      headers.Authorization = "Bearer " + token;
+    }
+
+    return fetch(url, { headers: headers, credentials: "same-origin" }).then(function (response) {
+      if (response.status === 401 && (response.headers.get("WWW-Authenticate") || "").indexOf("Bearer") === 0) {
+        var entered = window.prompt("API token");
+        if (entered) {
+          sessionStorage.setItem(tokenKey, entered);
+          return fetchJSON(url);
+        }
+      }
+      if (!response.ok) {
+        throw new Error(url + " returned " + response.status);
+      }
+      return response.json();
+    });
+  }
+
+  function daysRemaining(cert) {
+    if (!cert.expires_at) {
+      return null;
+    }
+    return Math.floor((new Date(cert.expires_at) - new Date()) / 86400000);
+  }
+
+  // lastNotification returns the newest delivery for a certificate
+  function lastNotification(cert) {
+    for (var i = 0; i < state.notifications.length; i++) {
+      var n = state.notifications[i];
+      if ((n.cluster || "") === (cert.cluster || "") && n.namespace === cert.namespace && n.name === cert.name) {
+        return n;
+      }
+    }
+    return null;
+  }
+
+  function outcome(n) {
+    if (n.dry_run) {
+      return "dry run";
+    }
+    return n.success ? "sent" : "failed";
+  }
+
+  function sortValue(cert, key) {
+    switch (key) {
+      case "days":
+        var days = daysRemaining(cert);
+        return days === null ? Number.MAX_SAFE_INTEGER : days;
+      case "ready":
+        return cert.ready ? 1 : 0;
+      case "notification":
+        var n = lastNotification(cert);
+        return n ? n.timestamp : "";
+      default:
+        return cert[key] || "";
+    }
+  }
+
+  function cell(row, text, className) {
+    var td = document.createElement("td");
+    td.textContent = text;
+    if (className) {
+      td.className = className;
+    }
+    row.appendChild(td);
+  }
+
+  function renderCertificates() {
+    var namespace = document.getElementById("namespace").value;
+    var rows = state.certificates.filter(function (cert) {
+      return !namespace || cert.namespace === namespace;
+    });
+
+    rows.sort(function (a, b) {
+      var x = sortValue(a, state.sortKey);
+      var y = sortValue(b, state.sortKey);
+      var result = x < y ? -1 : x > y ? 1 : 0;
+      return state.sortAsc ? result : -result;
+    });
+
+    var tbody = document.querySelector("#certificates tbody");
+    tbody.innerHTML = "";
+    rows.forEach(function (cert) {
+      var row = document.createElement("tr");
+      var days = daysRemaining(cert);
+      var n = lastNotification(cert);
+
+      cell(row, cert.cluster ? cert.cluster + "/" + cert.namespace : cert.namespace);
+      cell(row, cert.source === "unmanaged_secret" ? cert.name + " (unmanaged secret)" : cert.name);
+      cell(row, cert.issuer);
+      cell(row, days === null ? "-" : String(days));
+      cell(row, cert.ready ? "Yes" : "No");
+      cell(row, cert.silenced ? cert.state + " (silenced)" : cert.state, "state-" + cert.state);
+      if (n) {
+        cell(row, n.type + " via " + n.webhook + ": " + outcome(n), n.success ? "" : "failed");
+      } else {
+        cell(row, "-");
+      }
+      tbody.appendChild(row);
+    });
+
+    document.querySelectorAll("#certificates th").forEach(function (th) {
+      th.classList.remove("sorted-asc", "sorted-desc");
+      if (th.dataset.key === state.sortKey) {
+        th.classList.add(state.sortAsc ? "sorted-asc" : "sorted-desc");
+      }
+    });
+  }
+
+  function renderNotifications() {
+    var namespace = document.getElementById("namespace").value;
+    var tbody = document.querySelector("#notifications tbody");
+    tbody.innerHTML = "";
+
+    state.notifications.filter(function (n) {
+      return !namespace || n.namespace === namespace;
+    }).forEach(function (n) {
+      var row = document.createElement("tr");
+      cell(row, new Date(n.timestamp).toLocaleString());
+      cell(row, (n.cluster ? n.cluster + "/" : "") + n.namespace + "/" + n.name);
+      cell(row, n.type);
+      cell(row, n.webhook);
+      cell(row, n.dry_run ? "Dry run" : n.success ? "Sent (" + n.status_code + ")" : "Failed: " + n.error, n.success ? "" : "failed");
+      tbody.appendChild(row);
+    });
+  }
+
+  function renderNamespaces() {
+    var select = document.getElementById("namespace");
+    var selected = select.value;
+    var namespaces = {};
+    state.certificates.forEach(function (cert) {
+      namespaces[cert.namespace] = true;
+    });
+
+    while (select.options.length > 1) {
+      select.remove(1);
+    }
+    Object.keys(namespaces).sort().forEach(function (namespace) {
+      var option = document.createElement("option");
+      option.value = namespace;
+      option.textContent = namespace;
+      select.appendChild(option);
+    });
+    select.value = namespaces[selected] ? selected : "";
+  }
+
+  function render() {
+    renderCertificates();
+    renderNotifications();
+  }
+
+  function load() {
+    var error = document.getElementById("error");
+    Promise.all([
+      fetchJSON("../api/v1/certificates"),
+      fetchJSON("../api/v1/notifications?limit=50")
+    ]).then(function (results) {
+      state.certificates = results[0];
+      state.notifications = results[1];
+      error.hidden = true;
+      renderNamespaces();
+      render();
+    }).catch(function (err) {
+      error.textContent = "Failed to load data: " + err.message;
+      error.hidden = false;
+    });
+  }
+
+  document.querySelectorAll("#certificates th").forEach(function (th) {
+    th.addEventListener("click", function () {
+      if (state.sortKey === th.dataset.key) {
+        state.sortAsc = !state.sortAsc;
+      } else {
+        state.sortKey = th.dataset.key;
+        state.sortAsc = true;
+      }
+      renderCertificates();
+    });
+  });
+
+  document.getElementById("namespace").addEventListener("change", render);
+  document.getElementById("refresh").addEventListener("click", load);
+
+  load();
+  setInterval(load, 60000);
+})();
@@ -1,35 +1,44 @@
 package health
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"sync/atomic"
 	"time"
-)
 
-var (
-	healthy int64 = 1
+	"github.com/wiruzman/cert-manager-notifier/internal/metrics"
 )
 
 // HealthServer provides health check endpoints
 type HealthServer struct {
-	port int
+	port     int
+	registry *Registry
+	mux      *http.ServeMux
 }
 
 // NewHealthServer creates a new health server
-func NewHealthServer(port int) *HealthServer {
-	return &HealthServer{port: port}
+func NewHealthServer(port int, registry *Registry) *HealthServer {
+	h := &HealthServer{
+		port:     port,
+		registry: registry,
+		mux:      http.NewServeMux(),
+	}
+	h.mux.HandleFunc("/health", h.healthHandler)
+	h.mux.HandleFunc("/ready", h.readyHandler)
+	h.mux.Handle("/metrics", metrics.Handler())
+	return h
+}
+
+// HandleFunc registers an additional handler on the health server
+func (h *HealthServer) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	h.mux.HandleFunc(pattern, handler)
 }
 
 // Start starts the health check server
 func (h *HealthServer) Start() error {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/health", h.healthHandler)
-	mux.HandleFunc("/ready", h.readyHandler)
-
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", h.port),
-		Handler:      mux,
+		Handler:      h.mux,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
@@ -39,31 +48,21 @@ func (h *HealthServer) Start() error {
 
 // healthHandler handles liveness probe requests
 func (h *HealthServer) healthHandler(w http.ResponseWriter, r *http.Request) {
-	if atomic.LoadInt64(&healthy) == 1 {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("OK"))
-	} else {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_, _ = w.Write([]byte("Service Unavailable"))
-	}
+	h.writeReport(w, h.registry.Check(Liveness))
 }
 
 // readyHandler handles readiness probe requests
 func (h *HealthServer) readyHandler(w http.ResponseWriter, r *http.Request) {
-	if atomic.LoadInt64(&healthy) == 1 {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("Ready"))
-	} else {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_, _ = w.Write([]byte("Not Ready"))
-	}
+	h.writeReport(w, h.registry.Check(Readiness))
 }
 
-// SetHealthy sets the health status
-func SetHealthy(status bool) {
-	if status {
-		atomic.StoreInt64(&healthy, 1)
+// writeReport writes the report as JSON with a status code matching its health
+func (h *HealthServer) writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	if report.Healthy() {
+		w.WriteHeader(http.StatusOK)
 	} else {
-		atomic.StoreInt64(&healthy, 0)
+		w.WriteHeader(http.StatusServiceUnavailable)
 	}
+	_ = json.NewEncoder(w).Encode(report)
 }
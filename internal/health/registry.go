@@ -0,0 +1,113 @@
+package health
+
+import (
+	"sort"
+	"sync"
+)
+
+// Probe identifies the probes a component contributes to
+type Probe int
+
+const (
+	// Readiness marks a component as required for the readiness probe
+	Readiness Probe = 1 << iota
+	// Liveness marks a component as required for the liveness probe
+	Liveness
+)
+
+// Status values reported for components and probes
+const (
+	StatusOK           = "ok"
+	StatusFailing      = "failing"
+	StatusShuttingDown = "shutting_down"
+)
+
+// CheckFunc reports the health of a component; a nil error means healthy
+type CheckFunc func() error
+
+// ComponentStatus is the health of a single component
+type ComponentStatus struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Report is the detailed response of a probe
+type Report struct {
+	Status     string            `json:"status"`
+	Components []ComponentStatus `json:"components"`
+}
+
+// Healthy reports whether the probe succeeded
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// component is a registered health check
+type component struct {
+	probes Probe
+	check  CheckFunc
+}
+
+// Registry tracks the components that make up the application health
+type Registry struct {
+	components   map[string]component
+	shuttingDown bool
+	mutex        sync.RWMutex
+}
+
+// NewRegistry creates a new component registry
+func NewRegistry() *Registry {
+	return &Registry{
+		components: make(map[string]component),
+	}
+}
+
+// Register adds a component check to the given probes
+func (r *Registry) Register(name string, probes Probe, check CheckFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.components[name] = component{probes: probes, check: check}
+}
+
+// SetShuttingDown marks the application as shutting down, failing all probes
+func (r *Registry) SetShuttingDown() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.shuttingDown = true
+}
+
+// Check runs all component checks for a probe
+func (r *Registry) Check(probe Probe) Report {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	report := Report{
+		Status:     StatusOK,
+		Components: []ComponentStatus{},
+	}
+
+	for name, c := range r.components {
+		if c.probes&probe == 0 {
+			continue
+		}
+
+		status := ComponentStatus{Name: name, Status: StatusOK}
+		if err := c.check(); err != nil {
+			status.Status = StatusFailing
+			status.Message = err.Error()
+			report.Status = StatusFailing
+		}
+		report.Components = append(report.Components, status)
+	}
+
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Name < report.Components[j].Name
+	})
+
+	if r.shuttingDown {
+		report.Status = StatusShuttingDown
+	}
+
+	return report
+}
@@ -0,0 +1,49 @@
+package health
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegistry_Check(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("kubernetes", Readiness, func() error { return nil })
+	registry.Register("certificate-checks", Readiness|Liveness, func() error { return nil })
+	registry.Register("webhooks", Readiness, func() error { return errors.New("all webhooks failing") })
+
+	readiness := registry.Check(Readiness)
+	if readiness.Healthy() {
+		t.Error("Expected readiness to fail when a component is failing")
+	}
+
+	if len(readiness.Components) != 3 {
+		t.Fatalf("Expected 3 readiness components, got %d", len(readiness.Components))
+	}
+
+	if readiness.Components[2].Name != "webhooks" || readiness.Components[2].Message != "all webhooks failing" {
+		t.Errorf("Unexpected webhooks component status: %+v", readiness.Components[2])
+	}
+
+	liveness := registry.Check(Liveness)
+	if !liveness.Healthy() {
+		t.Errorf("Expected liveness to succeed, got %+v", liveness)
+	}
+
+	if len(liveness.Components) != 1 {
+		t.Errorf("Expected 1 liveness component, got %d", len(liveness.Components))
+	}
+}
+
+func TestRegistry_ShuttingDown(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("kubernetes", Readiness|Liveness, func() error { return nil })
+	registry.SetShuttingDown()
+
+	if registry.Check(Readiness).Healthy() {
+		t.Error("Expected readiness to fail while shutting down")
+	}
+
+	if registry.Check(Liveness).Healthy() {
+		t.Error("Expected liveness to fail while shutting down")
+	}
+}
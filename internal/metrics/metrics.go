@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "cert_manager_notifier"
+
+var (
+	certificateExpirySeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "certificate_expiry_seconds",
+		Help:      "Seconds until the certificate expires (negative if expired).",
+	}, []string{"cluster", "namespace", "name", "issuer"})
+
+	certificateReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "certificate_ready",
+		Help:      "Whether the certificate has the Ready condition set to True.",
+	}, []string{"cluster", "namespace", "name", "issuer"})
+
+	issuerReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "issuer_ready",
+		Help:      "Whether the Issuer or ClusterIssuer has the Ready condition set to True.",
+	}, []string{"cluster", "kind", "namespace", "name"})
+
+	issuerCAExpirySeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "issuer_ca_expiry_seconds",
+		Help:      "Seconds until the CA certificate of a CA issuer expires (negative if expired).",
+	}, []string{"cluster", "kind", "namespace", "name"})
+
+	certificates = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "certificates",
+		Help:      "Number of certificates found in the last check by state.",
+	}, []string{"cluster", "state"})
+
+	notificationsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_sent_total",
+		Help:      "Number of notifications successfully delivered.",
+	}, []string{"cluster", "webhook", "type"})
+
+	notificationsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_failed_total",
+		Help:      "Number of notifications that failed to be delivered.",
+	}, []string{"cluster", "webhook", "type"})
+
+	checkDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "check_duration_seconds",
+		Help:      "Duration of certificate checks.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"cluster"})
+
+	lastSuccessfulCheck = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_successful_check_timestamp_seconds",
+		Help:      "Unix timestamp of the last successful certificate check.",
+	}, []string{"cluster"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		certificateExpirySeconds,
+		certificateReady,
+		issuerReady,
+		issuerCAExpirySeconds,
+		certificates,
+		notificationsSent,
+		notificationsFailed,
+		checkDuration,
+		lastSuccessfulCheck,
+	)
+}
+
+// Handler returns the HTTP handler serving the metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ResetCertificates clears a cluster's per-certificate gauges so that deleted
+// certificates do not linger between checks
+func ResetCertificates(cluster string) {
+	certificateExpirySeconds.DeletePartialMatch(prometheus.Labels{"cluster": cluster})
+	certificateReady.DeletePartialMatch(prometheus.Labels{"cluster": cluster})
+}
+
+// ObserveCertificateExpiry records the time remaining until a certificate expires
+func ObserveCertificateExpiry(cluster, namespace, name, issuer string, remaining time.Duration) {
+	certificateExpirySeconds.WithLabelValues(cluster, namespace, name, issuer).Set(remaining.Seconds())
+}
+
+// ObserveCertificateReady records the ready status of a certificate
+func ObserveCertificateReady(cluster, namespace, name, issuer string, ready bool) {
+	value := 0.0
+	if ready {
+		value = 1
+	}
+	certificateReady.WithLabelValues(cluster, namespace, name, issuer).Set(value)
+}
+
+// ResetIssuers clears a cluster's per-issuer gauges so that deleted issuers
+// do not linger between checks
+func ResetIssuers(cluster string) {
+	issuerReady.DeletePartialMatch(prometheus.Labels{"cluster": cluster})
+	issuerCAExpirySeconds.DeletePartialMatch(prometheus.Labels{"cluster": cluster})
+}
+
+// ObserveIssuerReady records the ready status of an Issuer or ClusterIssuer
+func ObserveIssuerReady(cluster, kind, namespace, name string, ready bool) {
+	value := 0.0
+	if ready {
+		value = 1
+	}
+	issuerReady.WithLabelValues(cluster, kind, namespace, name).Set(value)
+}
+
+// ObserveIssuerCAExpiry records the time remaining until a CA issuer's CA certificate expires
+func ObserveIssuerCAExpiry(cluster, kind, namespace, name string, remaining time.Duration) {
+	issuerCAExpirySeconds.WithLabelValues(cluster, kind, namespace, name).Set(remaining.Seconds())
+}
+
+// SetCertificateCount records the number of certificates of a cluster in a state
+func SetCertificateCount(cluster, state string, count int) {
+	certificates.WithLabelValues(cluster, state).Set(float64(count))
+}
+
+// NotificationSent records a successfully delivered notification
+func NotificationSent(cluster, webhook, notificationType string) {
+	notificationsSent.WithLabelValues(cluster, webhook, notificationType).Inc()
+}
+
+// NotificationFailed records a notification that could not be delivered
+func NotificationFailed(cluster, webhook, notificationType string) {
+	notificationsFailed.WithLabelValues(cluster, webhook, notificationType).Inc()
+}
+
+// ObserveCheck records the duration of a cluster's certificate check and, if
+// it succeeded, the time it completed
+func ObserveCheck(cluster string, duration time.Duration, succeeded bool, completedAt time.Time) {
+	checkDuration.WithLabelValues(cluster).Observe(duration.Seconds())
+	if succeeded {
+		lastSuccessfulCheck.WithLabelValues(cluster).Set(float64(completedAt.Unix()))
+	}
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveCertificate(t *testing.T) {
+	ResetCertificates("")
+	ResetCertificates("prod")
+
+	ObserveCertificateExpiry("", "default", "test-cert", "letsencrypt", 2*time.Hour)
+	ObserveCertificateReady("", "default", "test-cert", "letsencrypt", true)
+	ObserveCertificateExpiry("prod", "default", "test-cert", "letsencrypt", time.Hour)
+
+	if value := testutil.ToFloat64(certificateExpirySeconds.WithLabelValues("", "default", "test-cert", "letsencrypt")); value != 7200 {
+		t.Errorf("Expected expiry 7200 seconds, got %v", value)
+	}
+
+	if value := testutil.ToFloat64(certificateReady.WithLabelValues("", "default", "test-cert", "letsencrypt")); value != 1 {
+		t.Errorf("Expected ready 1, got %v", value)
+	}
+
+	ResetCertificates("")
+
+	if count := testutil.CollectAndCount(certificateExpirySeconds); count != 1 {
+		t.Errorf("Expected only the other cluster's series after reset, got %d", count)
+	}
+
+	ResetCertificates("prod")
+
+	if count := testutil.CollectAndCount(certificateExpirySeconds); count != 0 {
+		t.Errorf("Expected no certificate series after reset, got %d", count)
+	}
+}
+
+func TestNotificationCounters(t *testing.T) {
+	NotificationSent("", "webhook-1", "expired")
+	NotificationSent("", "webhook-1", "expired")
+	NotificationFailed("", "webhook-1", "expiring")
+
+	if value := testutil.ToFloat64(notificationsSent.WithLabelValues("", "webhook-1", "expired")); value != 2 {
+		t.Errorf("Expected 2 sent notifications, got %v", value)
+	}
+
+	if value := testutil.ToFloat64(notificationsFailed.WithLabelValues("", "webhook-1", "expiring")); value != 1 {
+		t.Errorf("Expected 1 failed notification, got %v", value)
+	}
+}
+
+func TestObserveCheck(t *testing.T) {
+	completedAt := time.Unix(1700000000, 0)
+	ObserveCheck("", time.Second, true, completedAt)
+
+	if value := testutil.ToFloat64(lastSuccessfulCheck.WithLabelValues("")); value != 1700000000 {
+		t.Errorf("Expected last successful check 1700000000, got %v", value)
+	}
+
+	ObserveCheck("", time.Second, false, completedAt.Add(time.Hour))
+
+	if value := testutil.ToFloat64(lastSuccessfulCheck.WithLabelValues("")); value != 1700000000 {
+		t.Errorf("Expected failed check not to update timestamp, got %v", value)
+	}
+}
@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// Annotations written to Certificates after notifying about them
+const (
+	AnnotationLastNotified     = "cert-manager-notifier.io/last-notified"
+	AnnotationLastAlertType    = "cert-manager-notifier.io/last-alert-type"
+	AnnotationNextNotification = "cert-manager-notifier.io/next-notification"
+
+	// AnnotationNotifiedPrefix prefixes the alert type in the annotations
+	// recording when each type of notification was last sent
+	AnnotationNotifiedPrefix = "notified.cert-manager-notifier.io/"
+)
+
+// notificationInterval is how long a notification of the same type is held back
+const notificationInterval = 24 * time.Hour
+
+// reportNotification makes the outcome of a notification about a Certificate
+// visible in the cluster as an Event and, once sent, as annotations. Only
+// notifications to the global webhooks are reported.
+func (m *CertificateMonitor) reportNotification(ctx context.Context, cert *certmanagerv1.Certificate, notificationType string, err error, now time.Time) {
+	if notifiesPolicies(ctx) {
+		return
+	}
+
+	m.recordNotification(cert, notificationType, err)
+	if err != nil || !m.config.AnnotateCertificates || m.config.DryRun {
+		return
+	}
+
+	if err := m.annotateNotified(ctx, cert, notificationType, now); err != nil {
+		m.logger.WithError(err).WithField("certificate", cert.Name).Warn("Failed to annotate certificate")
+	}
+}
+
+// annotateNotified records when a Certificate was last notified about, with
+// which alert type, and when it may be notified about again. The time is
+// also recorded per alert type, so that every alert active at once is
+// remembered.
+func (m *CertificateMonitor) annotateNotified(ctx context.Context, cert *certmanagerv1.Certificate, notificationType string, now time.Time) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				AnnotationLastNotified:     now.UTC().Format(time.RFC3339),
+				AnnotationLastAlertType:    notificationType,
+				AnnotationNextNotification: now.Add(m.repeatInterval(ctx)).UTC().Format(time.RFC3339),
+
+				AnnotationNotifiedPrefix + notificationType: now.UTC().Format(time.RFC3339),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode annotations: %w", err)
+	}
+
+	_, err = m.client.CertmanagerV1().Certificates(cert.Namespace).Patch(ctx, cert.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to patch certificate: %w", err)
+	}
+	return nil
+}
+
+// restoreNotified picks up the notifications about a Certificate from its
+// annotations when this replica has no record of them, so a replica taking
+// over the namespace or restarting does not repeat them within the interval
+func (m *CertificateMonitor) restoreNotified(cert *certmanagerv1.Certificate) {
+	if !m.config.AnnotateCertificates {
+		return
+	}
+
+	notified := make(map[string]time.Time)
+	restore := func(alertType, value string) {
+		lastNotified, err := time.Parse(time.RFC3339, value)
+		if alertType == "" || err != nil {
+			return
+		}
+
+		// Expired and expiring notifications share the certificate's key,
+		// the other alert types are deduplicated on their own
+		key := cert.Namespace + "/" + cert.Name
+		if alertType != webhook.TypeExpired && alertType != webhook.TypeExpiring {
+			key += "#" + alertType
+		}
+		if lastNotified.After(notified[key]) {
+			notified[key] = lastNotified
+		}
+	}
+
+	// Certificates annotated before the per-type annotations only record
+	// the last alert type
+	restore(cert.Annotations[AnnotationLastAlertType], cert.Annotations[AnnotationLastNotified])
+	for name, value := range cert.Annotations {
+		if alertType, ok := strings.CutPrefix(name, AnnotationNotifiedPrefix); ok {
+			restore(alertType, value)
+		}
+	}
+
+	m.notifiedMutex.Lock()
+	defer m.notifiedMutex.Unlock()
+	for key, lastNotified := range notified {
+		if _, exists := m.notifiedCerts[key]; !exists {
+			m.notifiedCerts[key] = lastNotified
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+func TestCheckCertificates_Annotations(t *testing.T) {
+	now := time.Now()
+	certMonitor, _ := newTestMonitor(t, &config.Config{AnnotateCertificates: true}, []runtime.Object{
+		newTestCertificate("valid", now.Add(90*24*time.Hour)),
+		newTestCertificate("expiring", now.Add(10*24*time.Hour)),
+	}, nil)
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	certificates := certMonitor.client.CertmanagerV1().Certificates("default")
+	expiring, err := certificates.Get(context.Background(), "expiring", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get certificate: %v", err)
+	}
+
+	if expiring.Annotations[AnnotationLastAlertType] != "expiring" {
+		t.Errorf("Expected the expiring alert type, got %q", expiring.Annotations[AnnotationLastAlertType])
+	}
+	lastNotified, err := time.Parse(time.RFC3339, expiring.Annotations[AnnotationLastNotified])
+	if err != nil {
+		t.Fatalf("Expected a last notified time, got: %v", err)
+	}
+	nextNotification, err := time.Parse(time.RFC3339, expiring.Annotations[AnnotationNextNotification])
+	if err != nil {
+		t.Fatalf("Expected a next notification time, got: %v", err)
+	}
+	if nextNotification.Sub(lastNotified) != 24*time.Hour {
+		t.Errorf("Expected the next notification a day after the last, got %s and %s", lastNotified, nextNotification)
+	}
+
+	valid, err := certificates.Get(context.Background(), "valid", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get certificate: %v", err)
+	}
+	if len(valid.Annotations) != 0 {
+		t.Errorf("Expected no annotations on a certificate without notifications, got %v", valid.Annotations)
+	}
+}
+
+func TestCheckCertificates_RestoresNotifiedFromAnnotations(t *testing.T) {
+	now := time.Now()
+
+	// Notified by another replica an hour ago, e.g. before a rebalance
+	recent := newTestCertificate("recent", now.Add(-time.Hour))
+	recent.Annotations = map[string]string{
+		AnnotationLastNotified:  now.Add(-time.Hour).UTC().Format(time.RFC3339),
+		AnnotationLastAlertType: "expired",
+	}
+
+	// Notified longer ago than the repeat interval
+	stale := newTestCertificate("stale", now.Add(-time.Hour))
+	stale.Annotations = map[string]string{
+		AnnotationLastNotified:  now.Add(-48 * time.Hour).UTC().Format(time.RFC3339),
+		AnnotationLastAlertType: "expired",
+	}
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{AnnotateCertificates: true}, []runtime.Object{recent, stale}, nil)
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	payloads := recorder.received()
+	if len(payloads) != 1 || payloads[0].Certificate.Name != "stale" {
+		t.Errorf("Expected only the stale certificate to be notified about, got %+v", payloads)
+	}
+}
+
+func TestCheckCertificates_RestoresEveryAlertType(t *testing.T) {
+	now := time.Now()
+
+	// An expired certificate whose Secret is missing raises two alerts
+	cert := newTestCertificate("web", now.Add(-time.Hour))
+	certMonitor, recorder := newTestMonitor(t, &config.Config{AnnotateCertificates: true, InspectSecrets: true}, []runtime.Object{cert}, nil)
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if types := recorder.types(); len(types) != 2 {
+		t.Fatalf("Expected expired and secret_mismatch notifications, got %v", types)
+	}
+
+	annotated, err := certMonitor.client.CertmanagerV1().Certificates("default").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get certificate: %v", err)
+	}
+	for _, alertType := range []string{webhook.TypeExpired, webhook.TypeSecretMismatch} {
+		if _, ok := annotated.Annotations[AnnotationNotifiedPrefix+alertType]; !ok {
+			t.Errorf("Expected the %s notification to be annotated, got %v", alertType, annotated.Annotations)
+		}
+	}
+
+	// A replica taking over repeats neither alert
+	restarted, recorder := newTestMonitor(t, &config.Config{AnnotateCertificates: true, InspectSecrets: true}, []runtime.Object{annotated}, nil)
+	if _, err := restarted.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if types := recorder.types(); len(types) != 0 {
+		t.Errorf("Expected no repeated notifications, got %v", types)
+	}
+}
@@ -0,0 +1,254 @@
+package monitor
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/certinspect"
+	"github.com/wiruzman/cert-manager-notifier/internal/silence"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// unmanagedSecret is a TLS Secret referenced by Ingresses or Gateways that
+// is not managed by cert-manager
+type unmanagedSecret struct {
+	namespace    string
+	name         string
+	referencedBy []string
+	leaf         *x509.Certificate
+}
+
+// issuer names the issuer of the Secret's certificate
+func (s unmanagedSecret) issuer() string {
+	if s.leaf.Issuer.CommonName != "" {
+		return s.leaf.Issuer.CommonName
+	}
+	return s.leaf.Issuer.String()
+}
+
+// silenceTarget describes an unmanaged Secret in a cluster for matching silences
+func (s unmanagedSecret) silenceTarget(cluster string) silence.Target {
+	return silence.Target{
+		Cluster:   cluster,
+		Namespace: s.namespace,
+		Name:      s.name,
+		Issuer:    s.issuer(),
+		DNSNames:  s.leaf.DNSNames,
+	}
+}
+
+// unmanagedSecrets discovers and parses the TLS Secrets referenced by
+// Ingresses and Gateways when enabled, skipping Secrets that belong to a
+// cert-manager Certificate
+func (m *CertificateMonitor) unmanagedSecrets(ctx context.Context, certificates []certmanagerv1.Certificate) []unmanagedSecret {
+	if !m.config.DiscoverSecrets {
+		return nil
+	}
+
+	managed := make(map[string]bool, len(certificates))
+	for _, cert := range certificates {
+		managed[cert.Namespace+"/"+cert.Spec.SecretName] = true
+	}
+
+	// Secrets found through the objects that could be listed are still checked
+	references, err := m.secretReferences(ctx)
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to discover TLS secrets")
+	}
+
+	keys := make([]string, 0, len(references))
+	for key, secret := range references {
+		if !managed[key] && m.owns(secret.namespace) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	secrets := make([]unmanagedSecret, 0, len(keys))
+	for _, key := range keys {
+		secret := references[key]
+		sort.Strings(secret.referencedBy)
+
+		leaf, err := m.unmanagedLeaf(ctx, secret.namespace, secret.name)
+		if err != nil {
+			m.logger.WithError(err).WithField("secret", key).Warn("Failed to inspect discovered TLS secret")
+			continue
+		}
+		if leaf == nil {
+			continue
+		}
+
+		secret.leaf = leaf
+		secrets = append(secrets, *secret)
+	}
+
+	return secrets
+}
+
+// secretReferences lists the TLS Secrets referenced by Ingresses and Gateway
+// listeners, keyed by namespace and name. When Ingresses or Gateways cannot
+// be listed it returns the references found in the others with the error.
+func (m *CertificateMonitor) secretReferences(ctx context.Context) (map[string]*unmanagedSecret, error) {
+	namespace := metav1.NamespaceAll
+	if m.config.Namespace != "" {
+		namespace = m.config.Namespace
+	}
+
+	references := make(map[string]*unmanagedSecret)
+	add := func(secretNamespace, secretName, referencedBy string) {
+		key := secretNamespace + "/" + secretName
+		if references[key] == nil {
+			references[key] = &unmanagedSecret{namespace: secretNamespace, name: secretName}
+		}
+		references[key].referencedBy = append(references[key].referencedBy, referencedBy)
+	}
+
+	var errs []error
+
+	ingresses, err := m.kubeClient.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list ingresses: %w", err))
+		ingresses = &networkingv1.IngressList{}
+	}
+
+	for _, ingress := range ingresses.Items {
+		for _, tls := range ingress.Spec.TLS {
+			if tls.SecretName != "" {
+				add(ingress.Namespace, tls.SecretName, fmt.Sprintf("Ingress %s/%s", ingress.Namespace, ingress.Name))
+			}
+		}
+	}
+
+	gateways, err := m.gatewayClient.GatewayV1().Gateways(namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		// The Gateway API CRDs are not installed
+		m.logger.Debug("Gateway API not available, skipping gateways")
+		return references, errors.Join(errs...)
+	}
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list gateways: %w", err))
+		return references, errors.Join(errs...)
+	}
+
+	for _, gateway := range gateways.Items {
+		for _, listener := range gateway.Spec.Listeners {
+			if listener.TLS == nil {
+				continue
+			}
+
+			for _, ref := range listener.TLS.CertificateRefs {
+				if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != "Secret") {
+					continue
+				}
+
+				secretNamespace := gateway.Namespace
+				if ref.Namespace != nil {
+					secretNamespace = string(*ref.Namespace)
+				}
+				add(secretNamespace, string(ref.Name), fmt.Sprintf("Gateway %s/%s", gateway.Namespace, gateway.Name))
+			}
+		}
+	}
+
+	return references, errors.Join(errs...)
+}
+
+// unmanagedLeaf reads the leaf certificate from a discovered Secret. It
+// returns nil for missing Secrets and Secrets issued by cert-manager.
+func (m *CertificateMonitor) unmanagedLeaf(ctx context.Context, namespace, name string) (*x509.Certificate, error) {
+	secret, err := m.kubeClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+
+	if _, managed := secret.Annotations[certmanagerv1.CertificateNameKey]; managed {
+		return nil, nil
+	}
+
+	chain, err := certinspect.ParseCertificates(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", corev1.TLSCertKey, err)
+	}
+	return chain[0], nil
+}
+
+// unmanagedSecretState classifies an unmanaged Secret by its expiration
+func (m *CertificateMonitor) unmanagedSecretState(secret unmanagedSecret, now time.Time) string {
+	switch {
+	case now.After(secret.leaf.NotAfter):
+		return StateExpired
+	case secret.leaf.NotAfter.Sub(now) <= m.config.ExpirationThreshold:
+		return StateExpiring
+	default:
+		return StateOK
+	}
+}
+
+// unmanagedSecretStatus builds the view of an unmanaged Secret
+func (m *CertificateMonitor) unmanagedSecretStatus(secret unmanagedSecret, now time.Time) CertificateStatus {
+	expiresAt := secret.leaf.NotAfter
+	status := CertificateStatus{
+		Cluster:      m.cluster,
+		Namespace:    secret.namespace,
+		Name:         secret.name,
+		Issuer:       secret.issuer(),
+		DNSNames:     secret.leaf.DNSNames,
+		ExpiresAt:    &expiresAt,
+		Ready:        now.Before(expiresAt),
+		State:        m.unmanagedSecretState(secret, now),
+		Source:       webhook.SourceUnmanagedSecret,
+		ReferencedBy: secret.referencedBy,
+		CheckedAt:    now,
+	}
+
+	_, status.Silenced = m.silences.Match(secret.silenceTarget(m.cluster), now)
+	return status
+}
+
+// checkUnmanagedSecret notifies when an unmanaged Secret is expired or expiring soon
+func (m *CertificateMonitor) checkUnmanagedSecret(ctx context.Context, secret unmanagedSecret, now time.Time) error {
+	state := m.unmanagedSecretState(secret, now)
+	if state == StateOK {
+		return nil
+	}
+
+	if _, silenced := m.silences.Match(secret.silenceTarget(m.cluster), now); silenced {
+		return nil
+	}
+
+	secretKey := fmt.Sprintf("Secret/%s/%s", secret.namespace, secret.name)
+	if !m.shouldNotifyAlert(secretKey, state, notificationInterval, now) {
+		return nil
+	}
+
+	logger := m.logger.WithField("secret", secret.namespace+"/"+secret.name).WithField("expires_at", secret.leaf.NotAfter)
+	serialNumber := certinspect.SerialNumber(secret.leaf)
+
+	if state == StateExpired {
+		logger.Warn("Unmanaged TLS secret is expired")
+		if err := m.notifier.SendUnmanagedExpiredNotification(ctx, secret.name, secret.namespace, secret.issuer(), secret.leaf.DNSNames, secret.leaf.NotAfter, serialNumber, secret.referencedBy); err != nil {
+			return fmt.Errorf("failed to send expired notification: %w", err)
+		}
+	} else {
+		logger.Info("Unmanaged TLS secret is expiring soon")
+		if err := m.notifier.SendUnmanagedExpiringNotification(ctx, secret.name, secret.namespace, secret.issuer(), secret.leaf.DNSNames, secret.leaf.NotAfter, serialNumber, secret.referencedBy); err != nil {
+			return fmt.Errorf("failed to send expiring notification: %w", err)
+		}
+	}
+
+	m.markAlertNotified(secretKey, state, now)
+	return nil
+}
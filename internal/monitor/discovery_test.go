@@ -0,0 +1,119 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayfake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+func TestCheckCertificates_UnmanagedSecrets(t *testing.T) {
+	now := time.Now()
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{
+			{Hosts: []string{"web.example.com"}, SecretName: "web-tls"},
+			{Hosts: []string{"manual.example.com"}, SecretName: "manual-tls"},
+		}},
+	}
+	gateway := &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "public", Namespace: "default"},
+		Spec: gatewayv1.GatewaySpec{Listeners: []gatewayv1.Listener{
+			{Name: "manual", TLS: &gatewayv1.GatewayTLSConfig{CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "manual-tls"}}}},
+			{Name: "api", TLS: &gatewayv1.GatewayTLSConfig{CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "api-tls"}}}},
+		}},
+	}
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{DiscoverSecrets: true},
+		[]runtime.Object{newTestCertificate("web", now.Add(90*24*time.Hour))},
+		[]runtime.Object{
+			ingress,
+			newTestSecret(t, "web-tls", []string{"web.example.com"}, now.Add(90*24*time.Hour)),
+			newTestSecret(t, "manual-tls", []string{"manual.example.com"}, now.Add(5*24*time.Hour)),
+			newTestSecret(t, "api-tls", []string{"api.example.com"}, now.Add(90*24*time.Hour)),
+		})
+
+	// Gateway is registered under several API versions, so it is created
+	// through the client rather than seeded into the fake clientset
+	if _, err := certMonitor.gatewayClient.GatewayV1().Gateways("default").Create(context.Background(), gateway, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+
+	summary, err := certMonitor.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if summary.Total != 3 || summary.Expiring != 1 {
+		t.Errorf("Expected the certificate and two unmanaged secrets, got %+v", summary)
+	}
+
+	payloads := recorder.received()
+	if len(payloads) != 1 {
+		t.Fatalf("Expected 1 notification, got %v", recorder.types())
+	}
+
+	payload := payloads[0]
+	if payload.Type != "expiring" || payload.Source != webhook.SourceUnmanagedSecret || payload.Certificate.Name != "manual-tls" {
+		t.Errorf("Expected an expiring notification for the unmanaged secret, got %+v", payload)
+	}
+
+	if len(payload.ReferencedBy) != 2 || payload.ReferencedBy[0] != "Gateway default/public" || payload.ReferencedBy[1] != "Ingress default/web" {
+		t.Errorf("Expected the secret to be referenced by the gateway and ingress, got %v", payload.ReferencedBy)
+	}
+
+	sources := map[string]string{}
+	for _, status := range certMonitor.Certificates() {
+		sources[status.Name] = status.Source
+	}
+
+	if sources["web"] != webhook.SourceCertificate || sources["manual-tls"] != webhook.SourceUnmanagedSecret || sources["api-tls"] != webhook.SourceUnmanagedSecret {
+		t.Errorf("Unexpected sources: %v", sources)
+	}
+
+	if _, ok := sources["web-tls"]; ok {
+		t.Error("Expected the secret managed by cert-manager to be skipped")
+	}
+}
+
+func TestCheckCertificates_UnmanagedSecretsGatewaysForbidden(t *testing.T) {
+	now := time.Now()
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{
+			{Hosts: []string{"manual.example.com"}, SecretName: "manual-tls"},
+		}},
+	}
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{DiscoverSecrets: true}, nil, []runtime.Object{
+		ingress,
+		newTestSecret(t, "manual-tls", []string{"manual.example.com"}, now.Add(5*24*time.Hour)),
+	})
+
+	certMonitor.gatewayClient.(*gatewayfake.Clientset).PrependReactor("list", "gateways", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "gateway.networking.k8s.io", Resource: "gateways"}, "", errors.New("forbidden"))
+	})
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Secrets referenced by Ingresses are still checked
+	if types := recorder.types(); len(types) != 1 || types[0] != "expiring" {
+		t.Errorf("Expected an expiring notification for the ingress secret, got %v", types)
+	}
+}
@@ -0,0 +1,151 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	certmanagerscheme "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/scheme"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// eventComponent is the source of the Events the notifier records
+const eventComponent = "cert-manager-notifier"
+
+// Reasons of the Events recorded on Certificates
+const (
+	ReasonCertificateExpiring = "CertificateExpiring"
+	ReasonCertificateExpired  = "CertificateExpired"
+	ReasonNotificationSent    = "NotificationSent"
+	ReasonNotificationFailed  = "NotificationFailed"
+)
+
+// eventFlushTimeout bounds how long exiting waits for queued Events
+const eventFlushTimeout = 5 * time.Second
+
+// eventRecorder writes Events to the cluster in the background and counts
+// those not written yet, so a single check can wait for them before exiting
+type eventRecorder struct {
+	recorder    record.EventRecorder
+	broadcaster record.EventBroadcaster
+	pending     atomic.Int64
+}
+
+// newEventRecorder creates a recorder that writes Events to the cluster
+func newEventRecorder(kubeClient kubernetes.Interface, logger *logrus.Entry) *eventRecorder {
+	r := &eventRecorder{broadcaster: record.NewBroadcaster()}
+
+	sink := &typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")}
+	correlator := record.NewEventCorrelatorWithOptions(record.CorrelatorOptions{})
+	r.broadcaster.StartEventWatcher(func(event *corev1.Event) {
+		defer r.pending.Add(-1)
+		if err := writeEvent(sink, correlator, event); err != nil {
+			logger.WithError(err).WithField("reason", event.Reason).Warn("Failed to record event")
+		}
+	})
+
+	r.recorder = r.broadcaster.NewRecorder(certmanagerscheme.Scheme, corev1.EventSource{Component: eventComponent})
+	return r
+}
+
+// Event records an Event on an object
+func (r *eventRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	r.pending.Add(1)
+	r.recorder.Event(object, eventType, reason, message)
+}
+
+// Eventf records an Event on an object with a formatted message
+func (r *eventRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	r.pending.Add(1)
+	r.recorder.Eventf(object, eventType, reason, messageFmt, args...)
+}
+
+// AnnotatedEventf records an annotated Event on an object with a formatted message
+func (r *eventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	r.pending.Add(1)
+	r.recorder.AnnotatedEventf(object, annotations, eventType, reason, messageFmt, args...)
+}
+
+// Shutdown waits up to timeout for the queued Events to be written, then
+// stops the broadcaster. Events the recorder dropped are never written, so
+// the timeout also bounds the wait for those.
+func (r *eventRecorder) Shutdown(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for r.pending.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	r.broadcaster.Shutdown()
+}
+
+// writeEvent writes an Event to the cluster, aggregating repeated Events the
+// same way client-go's recorder does
+func writeEvent(sink record.EventSink, correlator *record.EventCorrelator, event *corev1.Event) error {
+	result, err := correlator.EventCorrelate(event)
+	if err != nil {
+		return fmt.Errorf("failed to correlate event: %w", err)
+	}
+	if result.Skip {
+		return nil
+	}
+
+	var written *corev1.Event
+	if result.Event.Count > 1 {
+		written, err = sink.Patch(result.Event, result.Patch)
+	}
+	// The aggregated Event may have been removed in the meantime
+	if result.Event.Count <= 1 || apierrors.IsNotFound(err) {
+		result.Event.ResourceVersion = ""
+		written, err = sink.Create(result.Event)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	correlator.UpdateState(written)
+	return nil
+}
+
+// Close writes the Events still queued and stops recording Events
+func (m *CertificateMonitor) Close() {
+	if recorder, ok := m.events.(*eventRecorder); ok {
+		recorder.Shutdown(eventFlushTimeout)
+	}
+}
+
+// recordExpired records a Warning Event on an expired Certificate
+func (m *CertificateMonitor) recordExpired(ctx context.Context, cert *certmanagerv1.Certificate, expiresAt time.Time) {
+	if m.events == nil || notifiesPolicies(ctx) {
+		return
+	}
+	m.events.Eventf(cert, corev1.EventTypeWarning, ReasonCertificateExpired, "Certificate expired at %s", expiresAt.UTC().Format(time.RFC3339))
+}
+
+// recordExpiring records a Warning Event on a Certificate that expires soon
+func (m *CertificateMonitor) recordExpiring(ctx context.Context, cert *certmanagerv1.Certificate, expiresAt time.Time) {
+	if m.events == nil || notifiesPolicies(ctx) {
+		return
+	}
+	m.events.Eventf(cert, corev1.EventTypeWarning, ReasonCertificateExpiring, "Certificate expires at %s", expiresAt.UTC().Format(time.RFC3339))
+}
+
+// recordNotification records the outcome of a notification about a
+// Certificate. The error is left out of the Event because it may contain
+// webhook URLs, which are secrets.
+func (m *CertificateMonitor) recordNotification(cert *certmanagerv1.Certificate, notificationType string, err error) {
+	if m.events == nil {
+		return
+	}
+	if err != nil {
+		m.events.Eventf(cert, corev1.EventTypeWarning, ReasonNotificationFailed, "Failed to send %s notification, see the notifier logs for details", notificationType)
+		return
+	}
+	m.events.Eventf(cert, corev1.EventTypeNormal, ReasonNotificationSent, "Sent %s notification", notificationType)
+}
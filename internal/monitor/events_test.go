@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+func TestCheckCertificates_Events(t *testing.T) {
+	now := time.Now()
+	certMonitor, recorder := newTestMonitor(t, &config.Config{}, []runtime.Object{
+		newTestCertificate("expired", now.Add(-24*time.Hour)),
+	}, nil)
+	events := record.NewFakeRecorder(10)
+	certMonitor.events = events
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Fail the next notification by taking the webhook down
+	recorder.server.Close()
+	_, err := certMonitor.client.CertmanagerV1().Certificates("default").Create(context.Background(), newTestCertificate("expiring", now.Add(10*24*time.Hour)), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []string{
+		"Warning CertificateExpired",
+		"Normal NotificationSent Sent expired notification",
+		"Warning CertificateExpiring",
+		"Warning NotificationFailed",
+	}
+	for _, prefix := range expected {
+		select {
+		case event := <-events.Events:
+			if !strings.HasPrefix(event, prefix) {
+				t.Errorf("Expected an event starting with %q, got %q", prefix, event)
+			}
+			if strings.Contains(event, recorder.server.URL) {
+				t.Errorf("Expected the event not to reveal the webhook URL, got %q", event)
+			}
+		default:
+			t.Fatalf("Expected an event starting with %q, got none", prefix)
+		}
+	}
+}
+
+func TestEventRecorder_Shutdown(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset()
+	recorder := newEventRecorder(kubeClient, logrus.NewEntry(logrus.New()))
+
+	cert := newTestCertificate("expired", time.Now().Add(-time.Hour))
+	for i := 0; i < 3; i++ {
+		recorder.Eventf(cert, corev1.EventTypeWarning, ReasonCertificateExpired, "Certificate expired")
+	}
+	recorder.Eventf(cert, corev1.EventTypeNormal, ReasonNotificationSent, "Sent expired notification")
+
+	// Exiting right after a check must not drop the queued Events
+	recorder.Shutdown(5 * time.Second)
+
+	events, err := kubeClient.CoreV1().Events("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	if len(events.Items) != 2 {
+		t.Fatalf("Expected the repeated event to be aggregated into 2 events, got %d", len(events.Items))
+	}
+	for _, event := range events.Items {
+		if event.Reason == ReasonCertificateExpired && event.Count != 3 {
+			t.Errorf("Expected the expired event to be counted 3 times, got %d", event.Count)
+		}
+	}
+}
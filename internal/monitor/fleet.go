@@ -0,0 +1,215 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/cluster"
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/policy"
+	"github.com/wiruzman/cert-manager-notifier/internal/shard"
+	"github.com/wiruzman/cert-manager-notifier/internal/silence"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// Fleet monitors the certificates of several clusters with an independent
+// CertificateMonitor per cluster. The clusters share the notifier and silences.
+type Fleet struct {
+	monitors []*CertificateMonitor
+	silences *silence.Store
+	shard    *shard.Coordinator
+	logger   *logrus.Entry
+}
+
+// NewFleet creates a certificate monitor for each cluster
+func NewFleet(clusters []cluster.Cluster, cfg *config.Config, notifier *webhook.Notifier, logger *logrus.Entry) (*Fleet, error) {
+	fleet := &Fleet{silences: silence.NewStore(), logger: logger.WithField("component", "fleet")}
+
+	for _, c := range clusters {
+		certMonitor, err := NewCertificateMonitor(c.Config, cfg, notifier, logger)
+		if err != nil {
+			return nil, clusterError(c.Name, err)
+		}
+
+		certMonitor.cluster = c.Name
+		certMonitor.silences = fleet.silences
+		if c.Name != "" {
+			certMonitor.logger = certMonitor.logger.WithField("cluster", c.Name)
+		}
+		fleet.monitors = append(fleet.monitors, certMonitor)
+	}
+
+	return fleet, nil
+}
+
+// EnableSharding makes every cluster's monitor check only the namespaces the
+// coordinator assigns to this replica
+func (f *Fleet) EnableSharding(coordinator *shard.Coordinator) {
+	f.shard = coordinator
+	for _, certMonitor := range f.monitors {
+		certMonitor.shard = coordinator
+	}
+}
+
+// PersistSilences makes every cluster's monitor share silences persisted in
+// backend, which are loaded before each check
+func (f *Fleet) PersistSilences(backend silence.Backend) {
+	f.silences = silence.NewPersistentStore(backend)
+	for _, certMonitor := range f.monitors {
+		certMonitor.silences = f.silences
+	}
+}
+
+// Reconfigure applies a reloaded configuration to every cluster from the next
+// check on, including its webhooks. The compliance policy is reloaded too, and
+// on failure to load it the previous configuration stays in effect.
+func (f *Fleet) Reconfigure(cfg *config.Config) error {
+	var certificatePolicy *policy.Policy
+	if cfg.PolicyFile != "" {
+		var err error
+		certificatePolicy, err = policy.Load(cfg.PolicyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load certificate policy: %w", err)
+		}
+	}
+
+	for _, certMonitor := range f.monitors {
+		certMonitor.reconfigure(cfg, certificatePolicy)
+	}
+	return nil
+}
+
+// Silences returns the silence store shared by all clusters
+func (f *Fleet) Silences() *silence.Store {
+	return f.silences
+}
+
+// Run monitors every cluster until the context is cancelled
+func (f *Fleet) Run(ctx context.Context) error {
+	errs := make([]error, len(f.monitors))
+
+	var wg sync.WaitGroup
+	if f.shard != nil {
+		// Join the other replicas before the first check so that it only
+		// covers this replica's namespaces
+		if err := f.shard.Sync(ctx); err != nil {
+			f.logger.WithError(err).Error("Failed to join shard group, checking all namespaces until it succeeds")
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.shard.Run(ctx)
+		}()
+	}
+
+	for i, certMonitor := range f.monitors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := certMonitor.Run(ctx); err != nil {
+				errs[i] = clusterError(certMonitor.cluster, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// CheckOnce checks every cluster once and sums up the results. Clusters that
+// cannot be checked are reported in the error while the others are still checked.
+func (f *Fleet) CheckOnce(ctx context.Context) (CheckSummary, error) {
+	var total CheckSummary
+	var errs []error
+
+	for _, certMonitor := range f.monitors {
+		summary, err := certMonitor.CheckOnce(ctx)
+		if err != nil {
+			errs = append(errs, clusterError(certMonitor.cluster, err))
+			continue
+		}
+
+		total.Total += summary.Total
+		total.Expired += summary.Expired
+		total.Expiring += summary.Expiring
+		total.Failed += summary.Failed
+		total.BrokenIssuers += summary.BrokenIssuers
+		total.ExpiringIssuers += summary.ExpiringIssuers
+	}
+
+	return total, errors.Join(errs...)
+}
+
+// Report returns the current status of the certificates of every cluster
+// without sending notifications. Clusters that cannot be listed are reported
+// in the error while the others are still returned.
+func (f *Fleet) Report(ctx context.Context) ([]CertificateStatus, error) {
+	var statuses []CertificateStatus
+	var errs []error
+	for _, certMonitor := range f.monitors {
+		clusterStatuses, err := certMonitor.Report(ctx)
+		if err != nil {
+			errs = append(errs, clusterError(certMonitor.cluster, err))
+			continue
+		}
+		statuses = append(statuses, clusterStatuses...)
+	}
+	return statuses, errors.Join(errs...)
+}
+
+// Certificates returns the certificates seen in the last check of every
+// cluster, ordered by cluster, namespace and name
+func (f *Fleet) Certificates() []CertificateStatus {
+	var statuses []CertificateStatus
+	for _, certMonitor := range f.monitors {
+		statuses = append(statuses, certMonitor.Certificates()...)
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Cluster < statuses[j].Cluster
+	})
+	return statuses
+}
+
+// SyncStatus reports the clusters whose certificates have not been listed yet
+func (f *Fleet) SyncStatus() error {
+	var errs []error
+	for _, certMonitor := range f.monitors {
+		if err := certMonitor.SyncStatus(); err != nil {
+			errs = append(errs, clusterError(certMonitor.cluster, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CheckStatus reports the clusters without a recent successful check
+func (f *Fleet) CheckStatus() error {
+	var errs []error
+	for _, certMonitor := range f.monitors {
+		if err := certMonitor.CheckStatus(); err != nil {
+			errs = append(errs, clusterError(certMonitor.cluster, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close writes the Events still queued for every cluster and stops recording Events
+func (f *Fleet) Close() {
+	for _, certMonitor := range f.monitors {
+		certMonitor.Close()
+	}
+}
+
+// clusterError prefixes an error with the name of the cluster it occurred in
+func clusterError(name string, err error) error {
+	if name == "" {
+		return err
+	}
+	return fmt.Errorf("cluster %s: %w", name, err)
+}
@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	certmanagerfake "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/silence"
+)
+
+func TestFleet_CheckOnce(t *testing.T) {
+	now := time.Now()
+
+	prod, prodRecorder := newTestMonitor(t, &config.Config{}, []runtime.Object{newTestCertificate("web", now.Add(-time.Hour))}, nil)
+	staging, stagingRecorder := newTestMonitor(t, &config.Config{}, []runtime.Object{newTestCertificate("web", now.Add(60*24*time.Hour))}, nil)
+	prod.cluster = "prod"
+	staging.cluster = "staging"
+
+	fleet := &Fleet{monitors: []*CertificateMonitor{staging, prod}, silences: silence.NewStore()}
+	prod.silences = fleet.silences
+	staging.silences = fleet.silences
+
+	summary, err := fleet.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if summary.Total != 2 || summary.Expired != 1 {
+		t.Errorf("Expected 2 certificates with 1 expired, got %+v", summary)
+	}
+
+	if payloads := prodRecorder.received(); len(payloads) != 1 || payloads[0].Cluster != "prod" {
+		t.Errorf("Expected an expired notification tagged with the prod cluster, got %+v", payloads)
+	}
+	if len(stagingRecorder.received()) != 0 {
+		t.Errorf("Expected no notifications for staging, got %v", stagingRecorder.types())
+	}
+
+	statuses := fleet.Certificates()
+	if len(statuses) != 2 || statuses[0].Cluster != "prod" || statuses[1].Cluster != "staging" {
+		t.Errorf("Expected statuses ordered by cluster, got %+v", statuses)
+	}
+}
+
+func TestFleet_Report(t *testing.T) {
+	now := time.Now()
+
+	prod, _ := newTestMonitor(t, &config.Config{}, []runtime.Object{newTestCertificate("web", now.Add(60*24*time.Hour))}, nil)
+	staging, _ := newTestMonitor(t, &config.Config{}, []runtime.Object{newTestCertificate("web", now.Add(60*24*time.Hour))}, nil)
+	prod.cluster = "prod"
+	staging.cluster = "staging"
+
+	staging.client.(*certmanagerfake.Clientset).PrependReactor("list", "certificates", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	fleet := &Fleet{monitors: []*CertificateMonitor{staging, prod}, silences: silence.NewStore()}
+
+	statuses, err := fleet.Report(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "staging") {
+		t.Errorf("Expected an error for the staging cluster, got: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Cluster != "prod" {
+		t.Errorf("Expected the prod certificates despite the staging error, got %+v", statuses)
+	}
+}
@@ -0,0 +1,173 @@
+package monitor
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	certmanagerfake "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	gatewayfake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/certtest"
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// recordingWebhook collects the payloads delivered to a test webhook server
+type recordingWebhook struct {
+	server   *httptest.Server
+	payloads []webhook.NotificationPayload
+	failing  map[string]bool
+	mutex    sync.Mutex
+}
+
+func newRecordingWebhook(t *testing.T) *recordingWebhook {
+	t.Helper()
+
+	recorder := &recordingWebhook{}
+	recorder.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhook.NotificationPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+
+		recorder.mutex.Lock()
+		defer recorder.mutex.Unlock()
+
+		if recorder.failing[payload.Type] {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		recorder.payloads = append(recorder.payloads, payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(recorder.server.Close)
+
+	return recorder
+}
+
+// fail makes the webhook reject notifications of the given type
+func (r *recordingWebhook) fail(notificationType string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.failing == nil {
+		r.failing = make(map[string]bool)
+	}
+	r.failing[notificationType] = true
+}
+
+// received returns the notifications received, in order
+func (r *recordingWebhook) received() []webhook.NotificationPayload {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]webhook.NotificationPayload(nil), r.payloads...)
+}
+
+// types returns the notification types received, in order
+func (r *recordingWebhook) types() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	types := make([]string, 0, len(r.payloads))
+	for _, payload := range r.payloads {
+		types = append(types, payload.Type)
+	}
+	return types
+}
+
+// newTestMonitor creates a monitor backed by fake clients and a recording webhook
+func newTestMonitor(t *testing.T, cfg *config.Config, certificates []runtime.Object, objects []runtime.Object) (*CertificateMonitor, *recordingWebhook) {
+	t.Helper()
+
+	recorder := newRecordingWebhook(t)
+	cfg.Webhooks = []config.WebhookConfig{
+		{Name: "test-webhook", URL: recorder.server.URL, Headers: map[string]string{}, Timeout: 5 * time.Second},
+	}
+	if cfg.CheckInterval == 0 {
+		cfg.CheckInterval = time.Hour
+	}
+	if cfg.ExpirationThreshold == 0 {
+		cfg.ExpirationThreshold = 30 * 24 * time.Hour
+	}
+
+	logger := logrus.NewEntry(logrus.New())
+
+	monitor, err := newCertificateMonitor(apiClients{
+		certManager: certmanagerfake.NewSimpleClientset(certificates...),
+		kube:        kubefake.NewSimpleClientset(objects...),
+		gateway:     gatewayfake.NewSimpleClientset(),
+	}, cfg, webhook.NewNotifier(cfg.Webhooks, logger), logger)
+	if err != nil {
+		t.Fatalf("Failed to create monitor: %v", err)
+	}
+	return monitor, recorder
+}
+
+// newTestCertificate creates a Certificate expiring at notAfter
+func newTestCertificate(name string, notAfter time.Time) *certmanagerv1.Certificate {
+	return &certmanagerv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: certmanagerv1.CertificateSpec{
+			SecretName: name + "-tls",
+			DNSNames:   []string{name + ".example.com"},
+			IssuerRef:  cmmeta.ObjectReference{Name: "letsencrypt"},
+		},
+		Status: certmanagerv1.CertificateStatus{
+			NotAfter: &metav1.Time{Time: notAfter.Truncate(time.Second)},
+			Conditions: []certmanagerv1.CertificateCondition{
+				{Type: certmanagerv1.CertificateConditionReady, Status: cmmeta.ConditionTrue},
+			},
+		},
+	}
+}
+
+// newTestSecret creates a TLS Secret holding a self-signed certificate for dnsNames
+func newTestSecret(t *testing.T, name string, dnsNames []string, notAfter time.Time) *corev1.Secret {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter.Truncate(time.Second),
+	}
+
+	_, _, certPEM := certtest.Issue(t, template, nil, nil)
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey: certPEM,
+		},
+	}
+}
+
+// waitFor polls condition until it holds, failing the test after a few seconds
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,274 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/certinspect"
+	"github.com/wiruzman/cert-manager-notifier/internal/metrics"
+	"github.com/wiruzman/cert-manager-notifier/internal/silence"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// reasonNoReadyCondition is reported for issuers without a Ready condition
+const reasonNoReadyCondition = "NoReadyCondition"
+
+// Issuer kinds a Certificate can reference
+const (
+	kindIssuer        = "Issuer"
+	kindClusterIssuer = "ClusterIssuer"
+)
+
+// issuerKey identifies an Issuer or ClusterIssuer in the notification state
+func issuerKey(kind, namespace, name string) string {
+	if kind == kindClusterIssuer {
+		return fmt.Sprintf("%s/%s", kind, name)
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// referencedIssuerKey returns the key of the cert-manager issuer a Certificate
+// references, or false for issuers of external issuer types
+func referencedIssuerKey(cert *certmanagerv1.Certificate) (string, bool) {
+	ref := cert.Spec.IssuerRef
+	if ref.Group != "" && ref.Group != "cert-manager.io" {
+		return "", false
+	}
+
+	switch ref.Kind {
+	case "", kindIssuer:
+		return issuerKey(kindIssuer, cert.Namespace, ref.Name), true
+	case kindClusterIssuer:
+		return issuerKey(kindClusterIssuer, "", ref.Name), true
+	default:
+		return "", false
+	}
+}
+
+// issuerSummary counts the unhealthy issuers found by a check
+type issuerSummary struct {
+	broken   int
+	expiring int
+	failed   int
+}
+
+// checkIssuers checks the health of Issuers and ClusterIssuers when enabled
+// and notifies about broken issuers, listing the certificates that reference them
+func (m *CertificateMonitor) checkIssuers(ctx context.Context, certificates []certmanagerv1.Certificate, now time.Time) issuerSummary {
+	var summary issuerSummary
+	if !m.config.MonitorIssuers {
+		return summary
+	}
+
+	affected := make(map[string][]webhook.CertificateRef)
+	for i := range certificates {
+		if key, ok := referencedIssuerKey(&certificates[i]); ok {
+			affected[key] = append(affected[key], webhook.CertificateRef{Namespace: certificates[i].Namespace, Name: certificates[i].Name})
+		}
+	}
+
+	issuers, err := m.getIssuers(ctx)
+	if err != nil {
+		m.logger.WithError(err).Error("Failed to list issuers")
+		summary.failed++
+		return summary
+	}
+
+	metrics.ResetIssuers(m.cluster)
+
+	for _, issuer := range issuers {
+		if !m.owns(issuer.GetNamespace()) {
+			continue
+		}
+
+		kind := kindIssuer
+		if issuer.GetNamespace() == "" {
+			kind = kindClusterIssuer
+		}
+
+		state, err := m.checkIssuer(ctx, kind, issuer, affected[issuerKey(kind, issuer.GetNamespace(), issuer.GetName())], now)
+		if err != nil {
+			m.logger.WithError(err).WithField("issuer", issuer.GetName()).Error("Failed to check issuer")
+			summary.failed++
+		}
+
+		switch state {
+		case StateExpired:
+			summary.broken++
+		case StateExpiring:
+			summary.expiring++
+		}
+	}
+
+	return summary
+}
+
+// getIssuers lists the Issuers in the monitored namespaces and all
+// ClusterIssuers, from the watch once it has synced
+func (m *CertificateMonitor) getIssuers(ctx context.Context) ([]certmanagerv1.GenericIssuer, error) {
+	if m.issuerWatch != nil && m.issuerWatch.synced() {
+		return m.issuerWatch.list()
+	}
+
+	namespace := metav1.NamespaceAll
+	if m.config.Namespace != "" {
+		namespace = m.config.Namespace
+	}
+
+	issuerList, err := m.client.CertmanagerV1().Issuers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issuers: %w", err)
+	}
+
+	clusterIssuerList, err := m.client.CertmanagerV1().ClusterIssuers().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster issuers: %w", err)
+	}
+
+	issuers := make([]certmanagerv1.GenericIssuer, 0, len(issuerList.Items)+len(clusterIssuerList.Items))
+	for i := range issuerList.Items {
+		issuers = append(issuers, &issuerList.Items[i])
+	}
+	for i := range clusterIssuerList.Items {
+		issuers = append(issuers, &clusterIssuerList.Items[i])
+	}
+	return issuers, nil
+}
+
+// checkIssuer notifies when an issuer is not Ready or its CA certificate is
+// expired or expiring soon. It returns StateExpired for issuers that cannot
+// issue certificates and StateExpiring for CA certificates expiring soon.
+func (m *CertificateMonitor) checkIssuer(ctx context.Context, kind string, issuer certmanagerv1.GenericIssuer, affected []webhook.CertificateRef, now time.Time) (string, error) {
+	details := webhook.IssuerDetails{
+		Kind:      kind,
+		Name:      issuer.GetName(),
+		Namespace: issuer.GetNamespace(),
+	}
+
+	sort.Slice(affected, func(i, j int) bool {
+		if affected[i].Namespace != affected[j].Namespace {
+			return affected[i].Namespace < affected[j].Namespace
+		}
+		return affected[i].Name < affected[j].Name
+	})
+
+	ready, condition := issuerReady(issuer)
+	metrics.ObserveIssuerReady(m.cluster, kind, details.Namespace, details.Name, ready)
+
+	state := StateOK
+	if !ready {
+		state = StateExpired
+
+		// An issuer cert-manager has not reported on yet cannot issue either
+		details.Reason = reasonNoReadyCondition
+		details.Message = "Issuer has no Ready condition"
+		if condition != nil {
+			details.Reason = condition.Reason
+			details.Message = condition.Message
+		}
+
+		if m.shouldNotifyIssuer(details, webhook.TypeIssuerNotReady, now) {
+			m.logger.WithField("issuer", details.String()).WithField("reason", details.Reason).WithField("affected_certificates", len(affected)).Warn("Issuer is not ready")
+
+			if err := m.notifier.SendIssuerNotReadyNotification(ctx, details, affected); err != nil {
+				return state, fmt.Errorf("failed to send issuer not ready notification: %w", err)
+			}
+			m.markAlertNotified(issuerKey(kind, details.Namespace, details.Name), webhook.TypeIssuerNotReady, now)
+		}
+	}
+
+	spec := issuer.GetSpec()
+	if spec.CA == nil || spec.CA.SecretName == "" {
+		return state, nil
+	}
+
+	secretNamespace := details.Namespace
+	if kind == kindClusterIssuer {
+		secretNamespace = m.config.ClusterResourceNamespace
+	}
+
+	caNotAfter, err := m.issuerCAExpiry(ctx, secretNamespace, spec.CA.SecretName)
+	if err != nil {
+		// cert-manager reports a missing or broken CA secret on the Ready condition
+		m.logger.WithError(err).WithField("issuer", details.String()).Debug("Failed to read issuer CA certificate")
+		return state, nil
+	}
+
+	details.CASecret = fmt.Sprintf("%s/%s", secretNamespace, spec.CA.SecretName)
+	details.CAExpiresAt = &caNotAfter
+	metrics.ObserveIssuerCAExpiry(m.cluster, kind, details.Namespace, details.Name, caNotAfter.Sub(now))
+
+	switch {
+	case now.After(caNotAfter):
+		state = StateExpired
+		if !m.shouldNotifyIssuer(details, webhook.TypeIssuerCAExpired, now) {
+			return state, nil
+		}
+
+		m.logger.WithField("issuer", details.String()).WithField("expires_at", caNotAfter).Warn("Issuer CA certificate is expired")
+
+		if err := m.notifier.SendIssuerCAExpiredNotification(ctx, details, affected); err != nil {
+			return state, fmt.Errorf("failed to send issuer CA expired notification: %w", err)
+		}
+		m.markAlertNotified(issuerKey(kind, details.Namespace, details.Name), webhook.TypeIssuerCAExpired, now)
+	case caNotAfter.Sub(now) <= m.config.ExpirationThreshold:
+		if state == StateOK {
+			state = StateExpiring
+		}
+		if !m.shouldNotifyIssuer(details, webhook.TypeIssuerCAExpiring, now) {
+			return state, nil
+		}
+
+		m.logger.WithField("issuer", details.String()).WithField("expires_at", caNotAfter).Info("Issuer CA certificate is expiring soon")
+
+		if err := m.notifier.SendIssuerCAExpiringNotification(ctx, details, affected); err != nil {
+			return state, fmt.Errorf("failed to send issuer CA expiring notification: %w", err)
+		}
+		m.markAlertNotified(issuerKey(kind, details.Namespace, details.Name), webhook.TypeIssuerCAExpiring, now)
+	}
+
+	return state, nil
+}
+
+// issuerReady reports whether the issuer's Ready condition is True, along
+// with the condition if the issuer has one
+func issuerReady(issuer certmanagerv1.GenericIssuer) (bool, *certmanagerv1.IssuerCondition) {
+	for i, condition := range issuer.GetStatus().Conditions {
+		if condition.Type == certmanagerv1.IssuerConditionReady {
+			return condition.Status == cmmeta.ConditionTrue, &issuer.GetStatus().Conditions[i]
+		}
+	}
+	return false, nil
+}
+
+// issuerCAExpiry returns the expiry of the CA certificate stored in a CA issuer's Secret
+func (m *CertificateMonitor) issuerCAExpiry(ctx context.Context, namespace, name string) (time.Time, error) {
+	secret, err := m.kubeClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+
+	chain, err := certinspect.ParseCertificates(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse secret %s/%s: %w", namespace, name, err)
+	}
+
+	return chain[0].NotAfter, nil
+}
+
+// shouldNotifyIssuer reports whether an issuer alert is neither silenced nor
+// already sent recently. Silences match issuers by namespace and issuer name.
+func (m *CertificateMonitor) shouldNotifyIssuer(issuer webhook.IssuerDetails, alertType string, now time.Time) bool {
+	target := silence.Target{Cluster: m.cluster, Namespace: issuer.Namespace, Issuer: issuer.Name}
+	if _, silenced := m.silences.Match(target, now); silenced {
+		return false
+	}
+	return m.shouldNotifyAlert(issuerKey(issuer.Kind, issuer.Namespace, issuer.Name), alertType, notificationInterval, now)
+}
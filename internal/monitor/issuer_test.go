@@ -0,0 +1,161 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	certmanagerfake "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+func TestCheckCertificates_Issuers(t *testing.T) {
+	now := time.Now()
+
+	acme := newTestCertificate("acme", now.Add(90*24*time.Hour))
+	acme.Spec.IssuerRef = cmmeta.ObjectReference{Name: "letsencrypt", Kind: "ClusterIssuer"}
+	internal := newTestCertificate("internal", now.Add(90*24*time.Hour))
+	internal.Spec.IssuerRef = cmmeta.ObjectReference{Name: "internal-ca"}
+
+	clusterIssuer := &certmanagerv1.ClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "letsencrypt"},
+		Spec: certmanagerv1.IssuerSpec{IssuerConfig: certmanagerv1.IssuerConfig{
+			ACME: &cmacme.ACMEIssuer{Server: "https://acme.example.com/directory"},
+		}},
+		Status: certmanagerv1.IssuerStatus{Conditions: []certmanagerv1.IssuerCondition{
+			{Type: certmanagerv1.IssuerConditionReady, Status: cmmeta.ConditionFalse, Reason: "ErrRegisterACMEAccount", Message: "Failed to register ACME account"},
+		}},
+	}
+	issuer := &certmanagerv1.Issuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "internal-ca", Namespace: "default"},
+		Spec: certmanagerv1.IssuerSpec{IssuerConfig: certmanagerv1.IssuerConfig{
+			CA: &certmanagerv1.CAIssuer{SecretName: "internal-ca"},
+		}},
+		Status: certmanagerv1.IssuerStatus{Conditions: []certmanagerv1.IssuerCondition{
+			{Type: certmanagerv1.IssuerConditionReady, Status: cmmeta.ConditionTrue},
+		}},
+	}
+	caSecret := newTestSecret(t, "internal-ca", []string{"Internal CA"}, now.Add(10*24*time.Hour))
+
+	// cert-manager has not reported on this issuer yet
+	pending := &certmanagerv1.Issuer{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"}}
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{MonitorIssuers: true},
+		[]runtime.Object{acme, internal, clusterIssuer, issuer, pending}, []runtime.Object{caSecret})
+
+	summary, err := certMonitor.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if summary.BrokenIssuers != 2 || summary.ExpiringIssuers != 1 {
+		t.Errorf("Expected 2 broken and 1 expiring issuer, got %+v", summary)
+	}
+
+	received := recorder.received()
+	payloads := map[string]webhook.NotificationPayload{}
+	for _, payload := range received {
+		payloads[payload.Type+"/"+payload.Issuer.Name] = payload
+	}
+
+	if len(received) != 3 {
+		t.Fatalf("Expected 3 notifications, got %v", recorder.types())
+	}
+
+	if notReady := payloads[webhook.TypeIssuerNotReady+"/pending"]; notReady.Issuer == nil || notReady.Issuer.Reason != reasonNoReadyCondition {
+		t.Errorf("Expected an issuer_not_ready notification for the issuer without a Ready condition, got %+v", notReady)
+	}
+
+	notReady, ok := payloads[webhook.TypeIssuerNotReady+"/letsencrypt"]
+	if !ok || notReady.Issuer == nil || notReady.Issuer.Reason != "ErrRegisterACMEAccount" {
+		t.Errorf("Expected an issuer_not_ready notification with the condition reason, got %+v", notReady)
+	}
+
+	if len(notReady.AffectedCertificates) != 1 || notReady.AffectedCertificates[0].Name != "acme" {
+		t.Errorf("Expected the acme certificate to be affected, got %+v", notReady.AffectedCertificates)
+	}
+
+	caExpiring, ok := payloads[webhook.TypeIssuerCAExpiring+"/internal-ca"]
+	if !ok || caExpiring.Issuer == nil || caExpiring.Issuer.CASecret != "default/internal-ca" {
+		t.Errorf("Expected an issuer_ca_expiring notification for the CA secret, got %+v", caExpiring)
+	}
+
+	if len(caExpiring.AffectedCertificates) != 1 || caExpiring.AffectedCertificates[0].Name != "internal" {
+		t.Errorf("Expected the internal certificate to be affected, got %+v", caExpiring.AffectedCertificates)
+	}
+
+	// A second check on the same day must not notify again
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(recorder.types()) != 3 {
+		t.Errorf("Expected no repeated notifications, got %v", recorder.types())
+	}
+}
+
+func TestWatchIssuers(t *testing.T) {
+	ready := &certmanagerv1.ClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "letsencrypt"},
+		Status: certmanagerv1.IssuerStatus{Conditions: []certmanagerv1.IssuerCondition{
+			{Type: certmanagerv1.IssuerConditionReady, Status: cmmeta.ConditionTrue},
+		}},
+	}
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{MonitorIssuers: true}, []runtime.Object{ready}, nil)
+	client := certMonitor.client.(*certmanagerfake.Clientset)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	certMonitor.watchIssuers(ctx)
+	if !cache.WaitForCacheSync(ctx.Done(), certMonitor.issuerWatch.synced) {
+		t.Fatal("Expected the issuer watch to sync")
+	}
+
+	// Wait for the informers to watch, as the fake client drops earlier events
+	waitFor(t, func() bool {
+		watches := 0
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "watch" {
+				watches++
+			}
+		}
+		return watches == 2
+	})
+
+	notReady := ready.DeepCopy()
+	notReady.Status.Conditions[0].Status = cmmeta.ConditionFalse
+	notReady.Status.Conditions[0].Reason = "ErrRegisterACMEAccount"
+	if _, err := client.CertmanagerV1().ClusterIssuers().UpdateStatus(ctx, notReady, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update issuer: %v", err)
+	}
+
+	select {
+	case <-certMonitor.issuersChanged:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the watch to report the issuer becoming not ready")
+	}
+
+	waitFor(t, func() bool {
+		issuers, err := certMonitor.getIssuers(ctx)
+		if err != nil || len(issuers) != 1 {
+			return false
+		}
+		isReady, _ := issuerReady(issuers[0])
+		return !isReady
+	})
+
+	certMonitor.recheckIssuers(ctx)
+	if types := recorder.types(); len(types) != 1 || types[0] != webhook.TypeIssuerNotReady {
+		t.Errorf("Expected an issuer_not_ready notification, got %v", types)
+	}
+}
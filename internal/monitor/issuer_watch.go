@@ -0,0 +1,128 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cminformers "github.com/cert-manager/cert-manager/pkg/client/informers/externalversions"
+	cmlisters "github.com/cert-manager/cert-manager/pkg/client/listers/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// issuerWatch keeps the Issuers and ClusterIssuers in an informer cache
+type issuerWatch struct {
+	namespace      string
+	issuers        cmlisters.IssuerLister
+	clusterIssuers cmlisters.ClusterIssuerLister
+	synced         func() bool
+	stop           context.CancelFunc
+}
+
+// list returns the cached Issuers and ClusterIssuers
+func (w *issuerWatch) list() ([]certmanagerv1.GenericIssuer, error) {
+	issuers, err := w.issuers.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issuers: %w", err)
+	}
+
+	clusterIssuers, err := w.clusterIssuers.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster issuers: %w", err)
+	}
+
+	generic := make([]certmanagerv1.GenericIssuer, 0, len(issuers)+len(clusterIssuers))
+	for _, issuer := range issuers {
+		generic = append(generic, issuer)
+	}
+	for _, clusterIssuer := range clusterIssuers {
+		generic = append(generic, clusterIssuer)
+	}
+	return generic, nil
+}
+
+// watchIssuers starts watching Issuers and ClusterIssuers when issuer
+// monitoring is enabled, restarting the watch when the monitored namespace
+// changed. Checks list issuers from the API until the watch has synced, and
+// an issuer becoming ready or not ready triggers an issuer check right away.
+func (m *CertificateMonitor) watchIssuers(ctx context.Context) {
+	namespace := metav1.NamespaceAll
+	if m.config.Namespace != "" {
+		namespace = m.config.Namespace
+	}
+
+	if m.issuerWatch != nil {
+		if m.config.MonitorIssuers && m.issuerWatch.namespace == namespace {
+			return
+		}
+		m.issuerWatch.stop()
+		m.issuerWatch = nil
+	}
+	if !m.config.MonitorIssuers {
+		return
+	}
+
+	factory := cminformers.NewSharedInformerFactoryWithOptions(m.client, 0, cminformers.WithNamespace(namespace))
+	issuers := factory.Certmanager().V1().Issuers()
+	clusterIssuers := factory.Certmanager().V1().ClusterIssuers()
+
+	handler := cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldIssuer, ok := oldObj.(certmanagerv1.GenericIssuer)
+			if !ok {
+				return
+			}
+			newIssuer, ok := newObj.(certmanagerv1.GenericIssuer)
+			if !ok {
+				return
+			}
+
+			wasReady, _ := issuerReady(oldIssuer)
+			isReady, _ := issuerReady(newIssuer)
+			if wasReady != isReady {
+				select {
+				case m.issuersChanged <- struct{}{}:
+				default:
+				}
+			}
+		},
+	}
+	for _, informer := range []cache.SharedIndexInformer{issuers.Informer(), clusterIssuers.Informer()} {
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			m.logger.WithError(err).Warn("Failed to watch issuers, listing them on every check")
+			return
+		}
+	}
+
+	watchCtx, stop := context.WithCancel(ctx)
+	factory.Start(watchCtx.Done())
+
+	m.issuerWatch = &issuerWatch{
+		namespace:      namespace,
+		issuers:        issuers.Lister(),
+		clusterIssuers: clusterIssuers.Lister(),
+		synced: func() bool {
+			return issuers.Informer().HasSynced() && clusterIssuers.Informer().HasSynced()
+		},
+		stop: stop,
+	}
+}
+
+// recheckIssuers checks the issuers between certificate checks, after the
+// watch saw one of them become ready or not ready
+func (m *CertificateMonitor) recheckIssuers(ctx context.Context) {
+	ctx = webhook.WithCluster(ctx, m.cluster)
+
+	certificates, err := m.getCertificates(ctx)
+	if err != nil {
+		m.logger.WithError(err).Error("Failed to get certificates for the issuer check")
+		return
+	}
+
+	m.checkIssuers(ctx, certificates.Items, time.Now())
+}
@@ -2,28 +2,64 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	certmanagerclient "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	gatewayclient "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 
 	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/metrics"
+	"github.com/wiruzman/cert-manager-notifier/internal/notificationpolicy"
+	"github.com/wiruzman/cert-manager-notifier/internal/policy"
+	"github.com/wiruzman/cert-manager-notifier/internal/shard"
+	"github.com/wiruzman/cert-manager-notifier/internal/silence"
 	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
 )
 
 // CertificateMonitor monitors cert-manager certificates
 type CertificateMonitor struct {
+	cluster       string
 	client        certmanagerclient.Interface
+	kubeClient    kubernetes.Interface
+	gatewayClient gatewayclient.Interface
 	config        *config.Config
 	notifier      *webhook.Notifier
 	logger        *logrus.Entry
 	notifiedCerts map[string]time.Time
 	notifiedMutex sync.RWMutex
+	silences      *silence.Store
+	policy        *policy.Policy
+	shard         *shard.Coordinator
+	events        record.EventRecorder
+	dynamicClient dynamic.Interface
+
+	notificationPolicies    *notificationpolicy.Set
+	notificationPolicyWatch *notificationpolicy.Watch
+
+	issuerWatch    *issuerWatch
+	issuersChanged chan struct{}
+
+	pending      *reconfiguration
+	pendingMutex sync.Mutex
+	reconfigured chan struct{}
+
+	startedAt           time.Time
+	synced              bool
+	lastSuccessfulCheck time.Time
+	lastCheckError      error
+	statuses            []CertificateStatus
+	statusMutex         sync.RWMutex
 }
 
 // NewCertificateMonitor creates a new certificate monitor
@@ -33,21 +69,107 @@ func NewCertificateMonitor(k8sConfig *rest.Config, cfg *config.Config, notifier
 		return nil, fmt.Errorf("failed to create cert-manager client: %w", err)
 	}
 
+	kubeClient, err := kubernetes.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	gatewayClient, err := gatewayclient.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gateway API client: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	return newCertificateMonitor(apiClients{
+		certManager: client,
+		kube:        kubeClient,
+		gateway:     gatewayClient,
+		dynamic:     dynamicClient,
+	}, cfg, notifier, logger)
+}
+
+// apiClients are the clients a monitor reads a cluster with
+type apiClients struct {
+	certManager certmanagerclient.Interface
+	kube        kubernetes.Interface
+	gateway     gatewayclient.Interface
+	dynamic     dynamic.Interface
+}
+
+// newCertificateMonitor creates a certificate monitor using the given clients
+func newCertificateMonitor(clients apiClients, cfg *config.Config, notifier *webhook.Notifier, logger *logrus.Entry) (*CertificateMonitor, error) {
+	var certificatePolicy *policy.Policy
+	if cfg.PolicyFile != "" {
+		var err error
+		certificatePolicy, err = policy.Load(cfg.PolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certificate policy: %w", err)
+		}
+	}
+
+	// Events would announce notifications that a dry run never sends
+	var events record.EventRecorder
+	if cfg.RecordEvents && !cfg.DryRun {
+		events = newEventRecorder(clients.kube, logger)
+	}
+
 	return &CertificateMonitor{
-		client:        client,
+		client:        clients.certManager,
+		kubeClient:    clients.kube,
+		gatewayClient: clients.gateway,
 		config:        cfg,
 		notifier:      notifier,
 		logger:        logger.WithField("component", "cert-monitor"),
 		notifiedCerts: make(map[string]time.Time),
+		silences:      silence.NewStore(),
+		policy:        certificatePolicy,
+		events:        events,
+		dynamicClient: clients.dynamic,
+		reconfigured:  make(chan struct{}, 1),
+		startedAt:     time.Now(),
+
+		issuersChanged: make(chan struct{}, 1),
 	}, nil
 }
 
+// Silences returns the silence store kept alongside the notification state
+func (m *CertificateMonitor) Silences() *silence.Store {
+	return m.silences
+}
+
+// CheckSummary summarizes the outcome of a certificate check
+type CheckSummary struct {
+	Total    int
+	Expired  int
+	Expiring int
+	Failed   int
+
+	// BrokenIssuers counts the issuers that are not Ready or whose CA
+	// certificate has expired, ExpiringIssuers those whose CA certificate
+	// expires within the threshold
+	BrokenIssuers   int
+	ExpiringIssuers int
+}
+
 // Run starts the certificate monitoring loop
 func (m *CertificateMonitor) Run(ctx context.Context) error {
 	m.logger.Info("Starting certificate monitor")
 
+	// Check namespaces taken over from other replicas right away
+	var rebalanced <-chan struct{}
+	if m.shard != nil {
+		rebalanced = m.shard.Subscribe()
+	}
+
+	m.watchIssuers(ctx)
+	m.watchNotificationPolicies(ctx)
+
 	// Initial check
-	if err := m.checkCertificates(ctx); err != nil {
+	if _, err := m.checkCertificates(ctx); err != nil {
 		m.logger.WithError(err).Error("Initial certificate check failed")
 	}
 
@@ -61,44 +183,231 @@ func (m *CertificateMonitor) Run(ctx context.Context) error {
 			m.logger.Info("Certificate monitor stopped")
 			return nil
 		case <-ticker.C:
-			if err := m.checkCertificates(ctx); err != nil {
+			if _, err := m.checkCertificates(ctx); err != nil {
 				m.logger.WithError(err).Error("Certificate check failed")
 			}
+		case <-m.issuersChanged:
+			m.recheckIssuers(ctx)
+		case <-rebalanced:
+			if _, err := m.checkCertificates(ctx); err != nil {
+				m.logger.WithError(err).Error("Certificate check after rebalancing failed")
+			}
+		case <-m.reconfigured:
+			// Check right away so that changed thresholds and webhooks take effect
+			if m.applyReconfiguration() {
+				ticker.Reset(m.config.CheckInterval)
+				m.watchIssuers(ctx)
+				m.watchNotificationPolicies(ctx)
+				if _, err := m.checkCertificates(ctx); err != nil {
+					m.logger.WithError(err).Error("Certificate check after reloading configuration failed")
+				}
+			}
 		}
 	}
 }
 
+// CheckOnce runs a single certificate check, sending notifications as needed
+func (m *CertificateMonitor) CheckOnce(ctx context.Context) (CheckSummary, error) {
+	return m.checkCertificates(ctx)
+}
+
+// Report returns the current status of all certificates without sending notifications
+func (m *CertificateMonitor) Report(ctx context.Context) ([]CertificateStatus, error) {
+	certificates, err := m.getCertificates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certificates: %w", err)
+	}
+
+	m.loadNotificationPolicies(ctx)
+	m.syncSilences(ctx)
+
+	now := time.Now()
+	renewals := make(renewalIndex)
+	statuses := make([]CertificateStatus, 0, len(certificates.Items))
+	for i := range certificates.Items {
+		cert := &certificates.Items[i]
+		inspection := m.prepareCertificate(ctx, cert)
+		status := m.certificateStatus(cert, inspection, m.diagnoseRenewal(ctx, renewals, cert, now), now)
+		status.PolicyViolations = m.evaluatePolicy(cert, inspection)
+		statuses = append(statuses, status)
+	}
+
+	for _, secret := range m.unmanagedSecrets(ctx, certificates.Items) {
+		statuses = append(statuses, m.unmanagedSecretStatus(secret, now))
+	}
+
+	return statuses, nil
+}
+
 // checkCertificates checks all certificates for expiration
-func (m *CertificateMonitor) checkCertificates(ctx context.Context) error {
+func (m *CertificateMonitor) checkCertificates(ctx context.Context) (CheckSummary, error) {
 	m.logger.Info("Checking certificates")
+	start := time.Now()
+	ctx = webhook.WithCluster(ctx, m.cluster)
 
 	// Get all certificates
 	certificates, err := m.getCertificates(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get certificates: %w", err)
+		metrics.ObserveCheck(m.cluster, time.Since(start), false, time.Now())
+		m.recordCheck(err, time.Now())
+		return CheckSummary{}, fmt.Errorf("failed to get certificates: %w", err)
 	}
 
-	m.logger.WithField("count", len(certificates.Items)).Info("Found certificates")
+	owned := m.ownedCertificates(certificates.Items)
+	m.logger.WithField("count", len(certificates.Items)).WithField("owned", len(owned)).Info("Found certificates")
+
+	m.loadNotificationPolicies(ctx)
+	m.syncSilences(ctx)
 
 	now := time.Now()
 	expiredCount := 0
 	expiringCount := 0
+	failedCount := 0
+
+	statuses := make([]CertificateStatus, 0, len(owned))
+	renewals := make(renewalIndex)
+
+	metrics.ResetCertificates(m.cluster)
+
+	for i := range owned {
+		cert := &owned[i]
+		m.restoreNotified(cert)
+		inspection := m.prepareCertificate(ctx, cert)
+		m.observeCertificate(cert, now)
+
+		renewal := m.diagnoseRenewal(ctx, renewals, cert, now)
+		endpoints, issuedSerial := m.probeCertificate(ctx, cert, inspection)
+		violations := m.evaluatePolicy(cert, inspection)
+
+		// Each check notifies on its own, so one failed delivery does not
+		// hold back the other alerts about the certificate
+		var errs []error
+		for _, certCtx := range m.notificationContexts(ctx, cert, now) {
+			errs = append(errs,
+				m.checkCertificate(certCtx, cert, inspection, now),
+				m.checkSecretMismatch(certCtx, cert, inspection, now),
+				m.checkRenewal(certCtx, cert, renewal, now),
+				m.checkRenewalWindow(certCtx, cert, now),
+				m.checkServedCertificate(certCtx, cert, inspection, endpoints, issuedSerial, now),
+				m.checkPolicy(certCtx, cert, violations, now),
+			)
+		}
+		err := errors.Join(errs...)
 
-	for i := range certificates.Items {
-		cert := &certificates.Items[i]
-		if err := m.checkCertificate(ctx, cert, now); err != nil {
+		status := m.certificateStatus(cert, inspection, renewal, now)
+		status.Endpoints = endpoints
+		status.PolicyViolations = violations
+		statuses = append(statuses, status)
+		if err != nil {
 			m.logger.WithError(err).WithField("certificate", cert.Name).Error("Failed to check certificate")
+			failedCount++
+			continue
+		}
+
+		switch status.State {
+		case StateExpired:
+			expiredCount++
+		case StateExpiring:
+			expiringCount++
+		}
+	}
+
+	for _, secret := range m.unmanagedSecrets(ctx, certificates.Items) {
+		status := m.unmanagedSecretStatus(secret, now)
+		statuses = append(statuses, status)
+		if err := m.checkUnmanagedSecret(ctx, secret, now); err != nil {
+			m.logger.WithError(err).WithField("secret", secret.name).Error("Failed to check unmanaged TLS secret")
+			failedCount++
 			continue
 		}
 
-		if m.isCertificateExpired(cert, now) {
+		if status.State == StateExpired {
 			expiredCount++
-		} else if m.isCertificateExpiring(cert, now) {
+		} else if status.State == StateExpiring {
 			expiringCount++
 		}
 	}
 
+	// The view also covers the certificates of the other replicas, while
+	// the counts and metrics only cover those checked here
+	m.setStatuses(append(statuses, m.otherShardStatuses(certificates.Items, now)...))
+
+	// Issuer notifications list the affected certificates of every shard
+	issuers := m.checkIssuers(ctx, certificates.Items, now)
+	failedCount += issuers.failed
+
+	metrics.SetCertificateCount(m.cluster, "total", len(statuses))
+	metrics.SetCertificateCount(m.cluster, "expired", expiredCount)
+	metrics.SetCertificateCount(m.cluster, "expiring", expiringCount)
+	metrics.ObserveCheck(m.cluster, time.Since(start), true, time.Now())
+	m.recordCheck(nil, time.Now())
+
 	m.logger.WithField("expired", expiredCount).WithField("expiring", expiringCount).Info("Certificate check completed")
+	return CheckSummary{
+		Total:    len(statuses),
+		Expired:  expiredCount,
+		Expiring: expiringCount,
+		Failed:   failedCount,
+
+		BrokenIssuers:   issuers.broken,
+		ExpiringIssuers: issuers.expiring,
+	}, nil
+}
+
+// observeCertificate records the per-certificate metrics
+func (m *CertificateMonitor) observeCertificate(cert *certmanagerv1.Certificate, now time.Time) {
+	issuer := m.getIssuerName(cert)
+	metrics.ObserveCertificateReady(m.cluster, cert.Namespace, cert.Name, issuer, m.isCertificateReady(cert))
+
+	if cert.Status.NotAfter != nil {
+		metrics.ObserveCertificateExpiry(m.cluster, cert.Namespace, cert.Name, issuer, cert.Status.NotAfter.Sub(now))
+	}
+}
+
+// recordCheck records the outcome of a certificate check for health reporting
+func (m *CertificateMonitor) recordCheck(err error, now time.Time) {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+
+	m.lastCheckError = err
+	if err == nil {
+		m.synced = true
+		m.lastSuccessfulCheck = now
+	}
+}
+
+// SyncStatus reports whether certificates have been listed successfully at least once
+func (m *CertificateMonitor) SyncStatus() error {
+	m.statusMutex.RLock()
+	defer m.statusMutex.RUnlock()
+
+	if m.synced {
+		return nil
+	}
+	if m.lastCheckError != nil {
+		return fmt.Errorf("waiting for first successful certificate list: %w", m.lastCheckError)
+	}
+	return fmt.Errorf("waiting for first successful certificate list")
+}
+
+// CheckStatus reports whether the last successful check is recent relative
+// to the check interval
+func (m *CertificateMonitor) CheckStatus() error {
+	m.statusMutex.RLock()
+	defer m.statusMutex.RUnlock()
+
+	since := m.lastSuccessfulCheck
+	if since.IsZero() {
+		since = m.startedAt
+	}
+
+	age := time.Since(since)
+	if age > 2*m.config.CheckInterval {
+		if m.lastCheckError != nil {
+			return fmt.Errorf("no successful check for %s: %w", age.Round(time.Second), m.lastCheckError)
+		}
+		return fmt.Errorf("no successful check for %s", age.Round(time.Second))
+	}
 	return nil
 }
 
@@ -115,26 +424,48 @@ func (m *CertificateMonitor) getCertificates(ctx context.Context) (*certmanagerv
 }
 
 // checkCertificate checks a single certificate for expiration
-func (m *CertificateMonitor) checkCertificate(ctx context.Context, cert *certmanagerv1.Certificate, now time.Time) error {
+func (m *CertificateMonitor) checkCertificate(ctx context.Context, cert *certmanagerv1.Certificate, inspection *secretInspection, now time.Time) error {
 	// Get certificate status
-	if cert.Status.NotAfter == nil {
+	expirationTime, ok := effectiveExpiry(cert, inspection)
+	if !ok {
 		m.logger.WithField("certificate", cert.Name).Debug("Certificate has no expiration date")
 		return nil
 	}
 
-	expirationTime := cert.Status.NotAfter.Time
-	certKey := fmt.Sprintf("%s/%s", cert.Namespace, cert.Name)
+	certKey := notificationKey(ctx, cert)
 
 	// Check if certificate is expired
-	if m.isCertificateExpired(cert, now) {
+	if m.isCertificateExpired(cert, inspection, now) {
+		if m.isSilenced(cert, now) {
+			return nil
+		}
+
 		// Check if we've already notified about this expired certificate today
-		if !m.shouldNotifyExpired(certKey, now) {
+		if !m.shouldNotifyExpired(certKey, m.repeatInterval(ctx), now) {
+			return nil
+		}
+
+		if inspection != nil && inspection.limitedBy != nil {
+			element := chainElementPayload(*inspection.limitedBy)
+			m.logger.WithField("certificate", cert.Name).WithField("expires_at", expirationTime).WithField("chain_element", element.Description).Warn("Certificate chain is expired")
+
+			m.recordExpired(ctx, cert, element.NotAfter)
+			err := m.notifier.SendChainExpiredNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, element)
+			m.reportNotification(ctx, cert, webhook.TypeExpired, err, now)
+			if err != nil {
+				return fmt.Errorf("failed to send expired notification: %w", err)
+			}
+
+			m.markNotified(certKey, now)
 			return nil
 		}
 
 		m.logger.WithField("certificate", cert.Name).WithField("expires_at", expirationTime).Warn("Certificate is expired")
 
-		if err := m.notifier.SendExpiredNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, expirationTime); err != nil {
+		m.recordExpired(ctx, cert, expirationTime)
+		err := m.notifier.SendExpiredNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, expirationTime)
+		m.reportNotification(ctx, cert, webhook.TypeExpired, err, now)
+		if err != nil {
 			return fmt.Errorf("failed to send expired notification: %w", err)
 		}
 
@@ -143,16 +474,44 @@ func (m *CertificateMonitor) checkCertificate(ctx context.Context, cert *certman
 	}
 
 	// Check if certificate is expiring soon
-	if m.isCertificateExpiring(cert, now) {
+	if m.isCertificateExpiring(cert, inspection, m.expirationThreshold(ctx), now) {
+		if !isExpiryDue(cert, inspection, now) {
+			m.logger.WithField("certificate", cert.Name).Debug("Certificate is expiring but not yet due for renewal")
+			return nil
+		}
+
+		if m.isSilenced(cert, now) {
+			return nil
+		}
+
 		// Check if we've already notified about this expiring certificate today
-		if !m.shouldNotifyExpiring(certKey, now) {
+		if !m.shouldNotifyExpiring(certKey, m.repeatInterval(ctx), now) {
 			return nil
 		}
 
 		daysUntilExpiry := int(time.Until(expirationTime).Hours() / 24)
+
+		if inspection != nil && inspection.limitedBy != nil {
+			element := chainElementPayload(*inspection.limitedBy)
+			m.logger.WithField("certificate", cert.Name).WithField("days_until_expiry", daysUntilExpiry).WithField("chain_element", element.Description).Info("Certificate chain is expiring soon")
+
+			m.recordExpiring(ctx, cert, element.NotAfter)
+			err := m.notifier.SendChainExpiringNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, element)
+			m.reportNotification(ctx, cert, webhook.TypeExpiring, err, now)
+			if err != nil {
+				return fmt.Errorf("failed to send expiring notification: %w", err)
+			}
+
+			m.markNotified(certKey, now)
+			return nil
+		}
+
 		m.logger.WithField("certificate", cert.Name).WithField("days_until_expiry", daysUntilExpiry).Info("Certificate is expiring soon")
 
-		if err := m.notifier.SendExpiringNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, expirationTime); err != nil {
+		m.recordExpiring(ctx, cert, expirationTime)
+		err := m.notifier.SendExpiringNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, expirationTime)
+		m.reportNotification(ctx, cert, webhook.TypeExpiring, err, now)
+		if err != nil {
 			return fmt.Errorf("failed to send expiring notification: %w", err)
 		}
 
@@ -163,24 +522,69 @@ func (m *CertificateMonitor) checkCertificate(ctx context.Context, cert *certman
 	return nil
 }
 
-// isCertificateExpired checks if a certificate is expired
-func (m *CertificateMonitor) isCertificateExpired(cert *certmanagerv1.Certificate, now time.Time) bool {
-	if cert.Status.NotAfter == nil {
-		return false
+// isCertificateReady checks if a certificate has the Ready condition set to True
+func (m *CertificateMonitor) isCertificateReady(cert *certmanagerv1.Certificate) bool {
+	for _, condition := range cert.Status.Conditions {
+		if condition.Type == certmanagerv1.CertificateConditionReady {
+			return condition.Status == cmmeta.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isCertificateExpired checks if a certificate or its chain is expired
+func (m *CertificateMonitor) isCertificateExpired(cert *certmanagerv1.Certificate, inspection *secretInspection, now time.Time) bool {
+	expiresAt, ok := effectiveExpiry(cert, inspection)
+	return ok && now.After(expiresAt)
+}
+
+// isCertificateExpiring checks if a certificate or its chain is expiring within the threshold
+func (m *CertificateMonitor) isCertificateExpiring(cert *certmanagerv1.Certificate, inspection *secretInspection, threshold time.Duration, now time.Time) bool {
+	expiresAt, ok := effectiveExpiry(cert, inspection)
+	return ok && now.Add(threshold).After(expiresAt)
+}
+
+// isSilenced checks whether notifications for a certificate are silenced,
+// either by a silence in the store or by annotations on the certificate
+func (m *CertificateMonitor) isSilenced(cert *certmanagerv1.Certificate, now time.Time) bool {
+	if _, err := silence.FromAnnotations(cert.Annotations); err != nil {
+		m.logger.WithError(err).WithField("certificate", cert.Name).Warn("Ignoring invalid silence annotation")
+	}
+
+	s, silenced := m.silencedBy(cert, now)
+	if silenced {
+		m.logger.WithField("certificate", cert.Name).WithField("silence", s.ID).WithField("author", s.Author).WithField("expires_at", s.ExpiresAt).Info("Certificate is silenced")
+	}
+	return silenced
+}
+
+// syncSilences picks up silences persisted by this or other replicas. When
+// they cannot be loaded the silences loaded before stay in effect.
+func (m *CertificateMonitor) syncSilences(ctx context.Context) {
+	if err := m.silences.Sync(ctx); err != nil {
+		m.logger.WithError(err).Error("Failed to load silences")
 	}
-	return now.After(cert.Status.NotAfter.Time)
 }
 
-// isCertificateExpiring checks if a certificate is expiring within the threshold
-func (m *CertificateMonitor) isCertificateExpiring(cert *certmanagerv1.Certificate, now time.Time) bool {
-	if cert.Status.NotAfter == nil {
-		return false
+// silencedBy returns the silence that applies to a certificate, if any
+func (m *CertificateMonitor) silencedBy(cert *certmanagerv1.Certificate, now time.Time) (silence.Silence, bool) {
+	annotationSilence, err := silence.FromAnnotations(cert.Annotations)
+	if err == nil && annotationSilence != nil && annotationSilence.Active(now) {
+		return *annotationSilence, true
+	}
+
+	target := silence.Target{
+		Cluster:   m.cluster,
+		Namespace: cert.Namespace,
+		Name:      cert.Name,
+		Issuer:    m.getIssuerName(cert),
+		DNSNames:  cert.Spec.DNSNames,
 	}
-	return now.Add(m.config.ExpirationThreshold).After(cert.Status.NotAfter.Time)
+	return m.silences.Match(target, now)
 }
 
 // shouldNotifyExpired checks if we should send an expired notification
-func (m *CertificateMonitor) shouldNotifyExpired(certKey string, now time.Time) bool {
+func (m *CertificateMonitor) shouldNotifyExpired(certKey string, interval time.Duration, now time.Time) bool {
 	m.notifiedMutex.RLock()
 	defer m.notifiedMutex.RUnlock()
 
@@ -189,12 +593,12 @@ func (m *CertificateMonitor) shouldNotifyExpired(certKey string, now time.Time)
 		return true
 	}
 
-	// Notify about expired certificates once per day
-	return now.Sub(lastNotified) >= 24*time.Hour
+	// Notify about expired certificates once per repeat interval
+	return now.Sub(lastNotified) >= interval
 }
 
 // shouldNotifyExpiring checks if we should send an expiring notification
-func (m *CertificateMonitor) shouldNotifyExpiring(certKey string, now time.Time) bool {
+func (m *CertificateMonitor) shouldNotifyExpiring(certKey string, interval time.Duration, now time.Time) bool {
 	m.notifiedMutex.RLock()
 	defer m.notifiedMutex.RUnlock()
 
@@ -203,8 +607,28 @@ func (m *CertificateMonitor) shouldNotifyExpiring(certKey string, now time.Time)
 		return true
 	}
 
-	// Notify about expiring certificates once per day
-	return now.Sub(lastNotified) >= 24*time.Hour
+	// Notify about expiring certificates once per repeat interval
+	return now.Sub(lastNotified) >= interval
+}
+
+// shouldNotifyAlert checks if we should send a notification of an additional
+// alert type, deduplicated independently of the expiry notifications
+func (m *CertificateMonitor) shouldNotifyAlert(certKey, alertType string, interval time.Duration, now time.Time) bool {
+	m.notifiedMutex.RLock()
+	defer m.notifiedMutex.RUnlock()
+
+	lastNotified, exists := m.notifiedCerts[certKey+"#"+alertType]
+	if !exists {
+		return true
+	}
+
+	// Notify about other alerts once per repeat interval
+	return now.Sub(lastNotified) >= interval
+}
+
+// markAlertNotified marks an additional alert type as having been notified
+func (m *CertificateMonitor) markAlertNotified(certKey, alertType string, now time.Time) {
+	m.markNotified(certKey+"#"+alertType, now)
 }
 
 // markNotified marks a certificate as having been notified
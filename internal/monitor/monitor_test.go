@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/silence"
+)
+
+func TestCheckCertificates(t *testing.T) {
+	now := time.Now()
+	certMonitor, recorder := newTestMonitor(t, &config.Config{}, []runtime.Object{
+		newTestCertificate("valid", now.Add(90*24*time.Hour)),
+		newTestCertificate("expiring", now.Add(10*24*time.Hour)),
+		newTestCertificate("expired", now.Add(-24*time.Hour)),
+	}, nil)
+
+	summary, err := certMonitor.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if summary.Total != 3 || summary.Expired != 1 || summary.Expiring != 1 || summary.Failed != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+
+	if len(recorder.types()) != 2 {
+		t.Errorf("Expected 2 notifications, got %v", recorder.types())
+	}
+
+	// A second check on the same day must not notify again
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(recorder.types()) != 2 {
+		t.Errorf("Expected no repeated notifications, got %v", recorder.types())
+	}
+}
+
+func TestCheckCertificates_Silenced(t *testing.T) {
+	now := time.Now()
+	annotated := newTestCertificate("annotated", now.Add(10*24*time.Hour))
+	annotated.Annotations = map[string]string{silence.AnnotationSilencedUntil: now.Add(time.Hour).UTC().Format(time.RFC3339)}
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{}, []runtime.Object{
+		annotated,
+		newTestCertificate("silenced", now.Add(10*24*time.Hour)),
+	}, nil)
+
+	_, err := certMonitor.Silences().Add(context.Background(), silence.Silence{
+		Matchers:  []silence.Matcher{{Name: silence.MatcherName, Value: "silenced"}},
+		Author:    "alice",
+		ExpiresAt: now.Add(time.Hour),
+	}, now)
+	if err != nil {
+		t.Fatalf("Failed to add silence: %v", err)
+	}
+
+	summary, err := certMonitor.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if summary.Expiring != 2 {
+		t.Errorf("Expected silenced certificates to still be tracked, got %+v", summary)
+	}
+
+	if len(recorder.types()) != 0 {
+		t.Errorf("Expected no notifications for silenced certificates, got %v", recorder.types())
+	}
+}
+
+func TestCheckCertificates_PersistedSilences(t *testing.T) {
+	now := time.Now()
+	certMonitor, recorder := newTestMonitor(t, &config.Config{}, []runtime.Object{
+		newTestCertificate("silenced", now.Add(10*24*time.Hour)),
+	}, nil)
+
+	// A silence created before the restart, or through another replica
+	backend := silence.NewConfigMap(certMonitor.kubeClient, "monitoring", "silences")
+	_, err := silence.NewPersistentStore(backend).Add(context.Background(), silence.Silence{
+		Matchers:  []silence.Matcher{{Name: silence.MatcherName, Value: "silenced"}},
+		Author:    "alice",
+		ExpiresAt: now.Add(time.Hour),
+	}, now)
+	if err != nil {
+		t.Fatalf("Failed to add silence: %v", err)
+	}
+
+	certMonitor.silences = silence.NewPersistentStore(backend)
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(recorder.types()) != 0 {
+		t.Errorf("Expected the persisted silence to apply, got %v", recorder.types())
+	}
+}
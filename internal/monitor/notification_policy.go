@@ -0,0 +1,154 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/notificationpolicy"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// notificationPolicyKey is the context key holding the policy destination a
+// round of checks notifies
+type notificationPolicyKey struct{}
+
+// policyDestination is a destination of the notification policies applying
+// to a Certificate
+type policyDestination struct {
+	resolved notificationpolicy.Resolved
+	webhook  string
+}
+
+// watchNotificationPolicies starts watching the notification policies when
+// they are enabled, restarting the watch when the monitored namespace
+// changed. Checks list the policies from the API until the watch has synced.
+func (m *CertificateMonitor) watchNotificationPolicies(ctx context.Context) {
+	namespace := metav1.NamespaceAll
+	if m.config.Namespace != "" {
+		namespace = m.config.Namespace
+	}
+
+	if m.notificationPolicyWatch != nil {
+		if m.config.NotificationPoliciesEnabled && m.notificationPolicyWatch.Namespace == namespace {
+			return
+		}
+		m.notificationPolicyWatch.Stop()
+		m.notificationPolicyWatch = nil
+	}
+	if !m.config.NotificationPoliciesEnabled || m.dynamicClient == nil {
+		return
+	}
+
+	m.notificationPolicyWatch = notificationpolicy.NewWatch(ctx, m.dynamicClient, namespace)
+}
+
+// loadNotificationPolicies refreshes the NotificationPolicies and
+// ClusterNotificationPolicies in effect. When they cannot be listed the
+// previous policies stay in effect.
+func (m *CertificateMonitor) loadNotificationPolicies(ctx context.Context) {
+	if !m.config.NotificationPoliciesEnabled {
+		return
+	}
+
+	var policies *notificationpolicy.Set
+	var err error
+	if m.notificationPolicyWatch != nil {
+		policies, err = m.notificationPolicyWatch.Load(ctx, m.kubeClient)
+	} else {
+		policies, err = notificationpolicy.Load(ctx, m.dynamicClient, m.kubeClient, m.config.Namespace)
+	}
+	if policies == nil {
+		m.logger.WithError(err).Error("Failed to load notification policies")
+		return
+	}
+	if err != nil {
+		m.logger.WithError(err).Warn("Ignoring invalid notification policies")
+	}
+
+	m.logger.WithField("count", policies.Len()).Debug("Loaded notification policies")
+	m.notificationPolicies = policies
+}
+
+// notificationPolicy merges the notification policies applying to a Certificate
+func (m *CertificateMonitor) notificationPolicy(cert *certmanagerv1.Certificate) notificationpolicy.Resolved {
+	return m.notificationPolicies.Match(cert.Namespace, cert.Labels)
+}
+
+// notificationContexts returns a context per set of destinations to notify
+// about a Certificate: the global webhooks, and each destination of its
+// notification policies while their schedule is open. Each is checked under
+// its own threshold and repeat interval and deduplicated on its own, so a
+// failed delivery to one destination is not repeated to the others.
+func (m *CertificateMonitor) notificationContexts(ctx context.Context, cert *certmanagerv1.Certificate, now time.Time) []context.Context {
+	contexts := []context.Context{ctx}
+
+	resolved := m.notificationPolicy(cert)
+	if len(resolved.Webhooks) == 0 {
+		return contexts
+	}
+	if !resolved.Open(now) {
+		m.logger.WithField("certificate", cert.Name).Debug("Holding back notifications to policy destinations outside their schedule")
+		return contexts
+	}
+
+	for _, destination := range resolved.Webhooks {
+		policyCtx := context.WithValue(ctx, notificationPolicyKey{}, policyDestination{resolved: resolved, webhook: destination.Name})
+		policyCtx = webhook.WithRoute(policyCtx, webhook.Route{Webhooks: []config.WebhookConfig{destination}, Message: resolved.Template})
+		contexts = append(contexts, policyCtx)
+	}
+	return contexts
+}
+
+// policyDestinationFromContext returns the policy destination the context
+// notifies, if it does not notify the global webhooks
+func policyDestinationFromContext(ctx context.Context) (policyDestination, bool) {
+	destination, ok := ctx.Value(notificationPolicyKey{}).(policyDestination)
+	return destination, ok
+}
+
+// notificationPolicyFromContext returns the notification policies whose
+// destination the context notifies, if it does not notify the global webhooks
+func notificationPolicyFromContext(ctx context.Context) (notificationpolicy.Resolved, bool) {
+	destination, ok := policyDestinationFromContext(ctx)
+	return destination.resolved, ok
+}
+
+// expirationThreshold returns how long before expiry a Certificate is
+// considered expiring by the destinations the context notifies
+func (m *CertificateMonitor) expirationThreshold(ctx context.Context) time.Duration {
+	if resolved, ok := notificationPolicyFromContext(ctx); ok && resolved.ExpirationThreshold > 0 {
+		return resolved.ExpirationThreshold
+	}
+	return m.config.ExpirationThreshold
+}
+
+// repeatInterval returns how long a notification to the destinations the
+// context notifies is not repeated
+func (m *CertificateMonitor) repeatInterval(ctx context.Context) time.Duration {
+	if resolved, ok := notificationPolicyFromContext(ctx); ok && resolved.RepeatInterval > 0 {
+		return resolved.RepeatInterval
+	}
+	return notificationInterval
+}
+
+// notificationKey deduplicates the notifications about a Certificate to the
+// destination the context notifies, apart from those to the global webhooks
+// and the other policy destinations
+func notificationKey(ctx context.Context, cert *certmanagerv1.Certificate) string {
+	key := cert.Namespace + "/" + cert.Name
+	if destination, ok := policyDestinationFromContext(ctx); ok {
+		key += "@" + destination.webhook
+	}
+	return key
+}
+
+// notifiesPolicies reports whether the context notifies the destinations of
+// notification policies rather than the global webhooks
+func notifiesPolicies(ctx context.Context) bool {
+	_, ok := notificationPolicyFromContext(ctx)
+	return ok
+}
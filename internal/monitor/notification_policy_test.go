@@ -0,0 +1,196 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/notificationpolicy"
+)
+
+// newTeamPolicy creates a NotificationPolicy routing the Certificates labelled
+// team=a to the URL in the team-webhook Secret
+func newTeamPolicy(spec map[string]any) *unstructured.Unstructured {
+	spec["selector"] = map[string]any{"matchLabels": map[string]any{"team": "a"}}
+	spec["destinations"] = []any{map[string]any{
+		"name":         "team",
+		"urlSecretRef": map[string]any{"name": "team-webhook", "key": "url"},
+	}}
+
+	policy := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	policy.SetAPIVersion(notificationpolicy.Group + "/" + notificationpolicy.Version)
+	policy.SetKind(notificationpolicy.KindNotificationPolicy)
+	policy.SetNamespace("default")
+	policy.SetName("team-a")
+	return policy
+}
+
+// newPolicyTestMonitor creates a monitor reading the policies, with a team
+// webhook whose URL is in the team-webhook Secret
+func newPolicyTestMonitor(t *testing.T, policy *unstructured.Unstructured, certificates []runtime.Object) (*CertificateMonitor, *recordingWebhook, *recordingWebhook) {
+	t.Helper()
+
+	team := newRecordingWebhook(t)
+	certMonitor, recorder := newTestMonitor(t, &config.Config{NotificationPoliciesEnabled: true}, certificates, []runtime.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-webhook", Namespace: "default"},
+			Data:       map[string][]byte{"url": []byte(team.server.URL)},
+		},
+	})
+	certMonitor.dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		notificationpolicy.NotificationPolicies:        "NotificationPolicyList",
+		notificationpolicy.ClusterNotificationPolicies: "ClusterNotificationPolicyList",
+	}, policy)
+	return certMonitor, recorder, team
+}
+
+func TestCheckCertificates_NotificationPolicies(t *testing.T) {
+	now := time.Now()
+
+	// Expiring only within the policy's threshold
+	routed := newTestCertificate("routed", now.Add(60*24*time.Hour))
+	routed.Labels = map[string]string{"team": "a"}
+	routed.Status.RenewalTime = &metav1.Time{Time: now.Add(-2 * time.Hour)}
+
+	// Expiring within both thresholds
+	both := newTestCertificate("both", now.Add(10*24*time.Hour))
+	both.Labels = map[string]string{"team": "a"}
+
+	certMonitor, recorder, team := newPolicyTestMonitor(t, newTeamPolicy(map[string]any{
+		"expirationThreshold": "90d",
+		"template":            "{{ .Certificate.Name }} expires soon",
+	}), []runtime.Object{
+		routed,
+		both,
+		newTestCertificate("global", now.Add(10*24*time.Hour)),
+		newTestCertificate("valid", now.Add(60*24*time.Hour)),
+	})
+
+	summary, err := certMonitor.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// The policy's threshold only applies to its destinations
+	if summary.Expiring != 2 {
+		t.Errorf("Expected 2 expiring certificates, got %+v", summary)
+	}
+
+	globalPayloads := recorder.received()
+	if len(globalPayloads) != 2 {
+		t.Fatalf("Expected the global webhook to receive 2 notifications, got %+v", globalPayloads)
+	}
+	for _, payload := range globalPayloads {
+		if payload.Certificate.Name == "routed" {
+			t.Errorf("Expected the global webhook not to get the policy's threshold")
+		}
+		if strings.HasSuffix(payload.Message, "expires soon") {
+			t.Errorf("Expected the global webhook to receive the default message, got %q", payload.Message)
+		}
+	}
+
+	// Both destinations are notified about the same certificate, as they are
+	// deduplicated apart
+	teamPayloads := team.received()
+	if len(teamPayloads) != 2 {
+		t.Fatalf("Expected the team webhook to receive 2 notifications, got %+v", teamPayloads)
+	}
+	for _, payload := range teamPayloads {
+		if payload.Certificate.Name == "global" {
+			t.Errorf("Expected the team webhook not to receive certificates outside its policy")
+		}
+		if payload.Message != payload.Certificate.Name+" expires soon" {
+			t.Errorf("Expected the templated message, got %q", payload.Message)
+		}
+	}
+
+	for _, status := range certMonitor.Certificates() {
+		if status.Name == "routed" && (len(status.NotificationPolicies) != 1 || status.NotificationPolicies[0] != "NotificationPolicy default/team-a") {
+			t.Errorf("Expected the routed certificate to report its policy, got %v", status.NotificationPolicies)
+		}
+	}
+}
+
+func TestCheckCertificates_NotificationPolicySchedule(t *testing.T) {
+	now := time.Now()
+
+	cert := newTestCertificate("expired", now.Add(-time.Hour))
+	cert.Labels = map[string]string{"team": "a"}
+
+	// Every day but today
+	var days []any
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if day != now.UTC().Weekday() {
+			days = append(days, day.String()[:3])
+		}
+	}
+
+	certMonitor, recorder, team := newPolicyTestMonitor(t, newTeamPolicy(map[string]any{
+		"schedule": map[string]any{"days": days},
+	}), []runtime.Object{cert})
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(recorder.received()) != 1 {
+		t.Errorf("Expected the global webhook to be notified regardless of the schedule, got %v", recorder.types())
+	}
+	if len(team.received()) != 0 {
+		t.Errorf("Expected the team webhook to be held back outside its schedule, got %v", team.types())
+	}
+}
+
+func TestCheckCertificates_NotificationPolicyDestinations(t *testing.T) {
+	now := time.Now()
+
+	cert := newTestCertificate("expired", now.Add(-time.Hour))
+	cert.Labels = map[string]string{"team": "a"}
+
+	policy := newTeamPolicy(map[string]any{})
+	policy.Object["spec"].(map[string]any)["destinations"] = []any{
+		map[string]any{"name": "team", "urlSecretRef": map[string]any{"name": "team-webhook", "key": "url"}},
+		map[string]any{"name": "ops", "urlSecretRef": map[string]any{"name": "ops-webhook", "key": "url"}},
+	}
+	certMonitor, _, team := newPolicyTestMonitor(t, policy, []runtime.Object{cert})
+
+	ops := newRecordingWebhook(t)
+	ops.fail("expired")
+	if _, err := certMonitor.kubeClient.CoreV1().Secrets("default").Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ops-webhook", Namespace: "default"},
+		Data:       map[string][]byte{"url": []byte(ops.server.URL)},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create the ops Secret: %v", err)
+	}
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(team.received()) != 1 || len(ops.received()) != 0 {
+		t.Fatalf("Expected only the team webhook to be notified, got %v and %v", team.types(), ops.types())
+	}
+
+	// Only the destination that failed is retried
+	ops.mutex.Lock()
+	ops.failing = nil
+	ops.mutex.Unlock()
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(team.received()) != 1 {
+		t.Errorf("Expected the team webhook not to be notified again, got %v", team.types())
+	}
+	if len(ops.received()) != 1 {
+		t.Errorf("Expected the ops webhook to be retried, got %v", ops.types())
+	}
+}
@@ -0,0 +1,145 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/policy"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// defaultCertificateDuration is the duration cert-manager uses when none is set
+const defaultCertificateDuration = 90 * 24 * time.Hour
+
+// evaluatePolicy checks the Certificate, and the issued certificate if the
+// Secret was inspected, against the policy when one is configured
+func (m *CertificateMonitor) evaluatePolicy(cert *certmanagerv1.Certificate, inspection *secretInspection) []string {
+	if m.policy == nil {
+		return nil
+	}
+
+	violations := m.policy.Evaluate(certificatePolicySubject(cert))
+
+	leaf := inspection.leaf()
+	if leaf == nil {
+		return violations
+	}
+
+	seen := make(map[string]bool, len(violations))
+	for _, violation := range violations {
+		seen[violation] = true
+	}
+
+	for _, violation := range m.policy.Evaluate(issuedPolicySubject(cert, leaf)) {
+		if !seen[violation] {
+			violations = append(violations, "issued certificate: "+violation)
+		}
+	}
+
+	return violations
+}
+
+// certificatePolicySubject describes a Certificate's spec for policy evaluation,
+// applying cert-manager's defaults
+func certificatePolicySubject(cert *certmanagerv1.Certificate) policy.Subject {
+	subject := policy.Subject{
+		Namespace:          cert.Namespace,
+		IssuerKind:         policyIssuerKind(cert),
+		Issuer:             cert.Spec.IssuerRef.Name,
+		KeyAlgorithm:       policy.AlgorithmRSA,
+		Duration:           defaultCertificateDuration,
+		RenewBeforeDefined: cert.Spec.RenewBefore != nil || cert.Spec.RenewBeforePercentage != nil,
+		DNSNames:           cert.Spec.DNSNames,
+	}
+
+	if cert.Spec.PrivateKey != nil {
+		if cert.Spec.PrivateKey.Algorithm != "" {
+			subject.KeyAlgorithm = string(cert.Spec.PrivateKey.Algorithm)
+		}
+		subject.KeySize = cert.Spec.PrivateKey.Size
+	}
+
+	if subject.KeySize == 0 {
+		switch subject.KeyAlgorithm {
+		case policy.AlgorithmRSA:
+			subject.KeySize = 2048
+		case policy.AlgorithmECDSA:
+			subject.KeySize = 256
+		}
+	}
+
+	if cert.Spec.Duration != nil {
+		subject.Duration = cert.Spec.Duration.Duration
+	}
+
+	return subject
+}
+
+// policyIssuerKind returns the kind of the issuer a Certificate references
+// as matched by the policy, qualified by the API group for external issuers
+func policyIssuerKind(cert *certmanagerv1.Certificate) string {
+	ref := cert.Spec.IssuerRef
+	kind := ref.Kind
+	if kind == "" {
+		kind = kindIssuer
+	}
+	if ref.Group != "" && ref.Group != "cert-manager.io" {
+		kind += "." + ref.Group
+	}
+	return kind
+}
+
+// issuedPolicySubject describes the issued certificate for policy evaluation
+func issuedPolicySubject(cert *certmanagerv1.Certificate, leaf *x509.Certificate) policy.Subject {
+	subject := certificatePolicySubject(cert)
+	subject.Duration = leaf.NotAfter.Sub(leaf.NotBefore)
+	subject.DNSNames = leaf.DNSNames
+
+	switch key := leaf.PublicKey.(type) {
+	case *rsa.PublicKey:
+		subject.KeyAlgorithm = policy.AlgorithmRSA
+		subject.KeySize = key.N.BitLen()
+	case *ecdsa.PublicKey:
+		subject.KeyAlgorithm = policy.AlgorithmECDSA
+		subject.KeySize = key.Curve.Params().BitSize
+	default:
+		subject.KeyAlgorithm = leaf.PublicKeyAlgorithm.String()
+		subject.KeySize = 0
+	}
+
+	return subject
+}
+
+// checkPolicy notifies when a certificate violates the policy
+func (m *CertificateMonitor) checkPolicy(ctx context.Context, cert *certmanagerv1.Certificate, violations []string, now time.Time) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	certKey := notificationKey(ctx, cert)
+	if m.isSilenced(cert, now) || !m.shouldNotifyAlert(certKey, webhook.TypePolicyViolation, m.repeatInterval(ctx), now) {
+		return nil
+	}
+
+	m.logger.WithField("certificate", cert.Name).WithField("violations", violations).Warn("Certificate violates policy")
+
+	var expiresAt time.Time
+	if cert.Status.NotAfter != nil {
+		expiresAt = cert.Status.NotAfter.Time
+	}
+
+	err := m.notifier.SendPolicyViolationNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, expiresAt, violations)
+	m.reportNotification(ctx, cert, webhook.TypePolicyViolation, err, now)
+	if err != nil {
+		return fmt.Errorf("failed to send policy violation notification: %w", err)
+	}
+
+	m.markAlertNotified(certKey, webhook.TypePolicyViolation, now)
+	return nil
+}
@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/policy"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+func TestCheckCertificates_PolicyViolation(t *testing.T) {
+	now := time.Now()
+	cert := newTestCertificate("web", now.Add(60*24*time.Hour))
+	secret := newTestSecret(t, "web-tls", []string{"web.example.com"}, now.Add(60*24*time.Hour))
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{InspectSecrets: true}, []runtime.Object{cert}, []runtime.Object{secret})
+	certMonitor.policy = &policy.Policy{
+		MinKeySize:         map[string]int{policy.AlgorithmECDSA: 384},
+		RequireRenewBefore: true,
+		AllowedIssuers:     map[string][]string{"*": {"Issuer/internal-ca"}},
+	}
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	payloads := recorder.received()
+	if len(payloads) != 1 || payloads[0].Type != webhook.TypePolicyViolation {
+		t.Fatalf("Expected a policy_violation notification, got %v", recorder.types())
+	}
+
+	expected := []string{
+		"issuer Issuer/letsencrypt is not allowed in namespace default (allowed: Issuer/internal-ca)",
+		"renewBefore or renewBeforePercentage is not set",
+		"issued certificate: ECDSA key size 256 is below the minimum of 384",
+	}
+	details := payloads[0].Details
+	if len(details) != len(expected) {
+		t.Fatalf("Expected %d violations, got %v", len(expected), details)
+	}
+	for i := range expected {
+		if details[i] != expected[i] {
+			t.Errorf("Expected violation %q, got %q", expected[i], details[i])
+		}
+	}
+
+	statuses := certMonitor.Certificates()
+	if len(statuses) != 1 || len(statuses[0].PolicyViolations) != 3 {
+		t.Errorf("Expected the status to include the violations, got %+v", statuses)
+	}
+}
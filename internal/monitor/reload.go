@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/policy"
+)
+
+// reconfiguration is a reloaded configuration waiting to be applied
+type reconfiguration struct {
+	config *config.Config
+	policy *policy.Policy
+}
+
+// reconfigure queues a configuration and compliance policy for the monitor
+// loop, replacing any configuration still waiting
+func (m *CertificateMonitor) reconfigure(cfg *config.Config, certificatePolicy *policy.Policy) {
+	m.pendingMutex.Lock()
+	m.pending = &reconfiguration{config: cfg, policy: certificatePolicy}
+	m.pendingMutex.Unlock()
+
+	select {
+	case m.reconfigured <- struct{}{}:
+	default:
+	}
+}
+
+// applyReconfiguration swaps in the queued configuration and its webhooks.
+// It runs between checks, so a check never sees a mix of old and new settings.
+func (m *CertificateMonitor) applyReconfiguration() bool {
+	m.pendingMutex.Lock()
+	pending := m.pending
+	m.pending = nil
+	m.pendingMutex.Unlock()
+
+	if pending == nil {
+		return false
+	}
+
+	// The health checks read the check interval concurrently
+	m.statusMutex.Lock()
+	m.config = pending.config
+	m.statusMutex.Unlock()
+
+	m.policy = pending.policy
+	if m.notifier != nil {
+		m.notifier.SetWebhooks(pending.config.Webhooks)
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+func TestReconfigure_Webhooks(t *testing.T) {
+	certMonitor, recorder := newTestMonitor(t, &config.Config{}, []runtime.Object{
+		newTestCertificate("expired", time.Now().Add(-time.Hour)),
+	}, nil)
+
+	replacement := newRecordingWebhook(t)
+	reloaded := *certMonitor.config
+	reloaded.Webhooks = []config.WebhookConfig{
+		{Name: "replacement", URL: replacement.server.URL, Headers: map[string]string{}, Timeout: 5 * time.Second},
+	}
+	certMonitor.reconfigure(&reloaded, nil)
+
+	// The webhooks only change between checks, with the rest of the configuration
+	if webhooks := certMonitor.notifier.Webhooks(); len(webhooks) != 1 || webhooks[0].Name != "test-webhook" {
+		t.Fatalf("Expected the running webhooks until the configuration is applied, got %+v", webhooks)
+	}
+	if !certMonitor.applyReconfiguration() {
+		t.Fatal("Expected the queued configuration to be applied")
+	}
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(recorder.received()) != 0 {
+		t.Errorf("Expected no notifications to the replaced webhook, got %v", recorder.types())
+	}
+	if len(replacement.received()) != 1 {
+		t.Errorf("Expected the replacement webhook to be notified, got %v", replacement.types())
+	}
+}
@@ -0,0 +1,253 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// renewalStuckAfter is how long an issuance may be in progress before it is
+// considered stuck
+const renewalStuckAfter = time.Hour
+
+// renewalProblem describes why a Certificate is failing to renew, or returns
+// false if its renewal looks healthy
+func renewalProblem(cert *certmanagerv1.Certificate, now time.Time) (string, bool) {
+	if cert.Status.LastFailureTime != nil {
+		return fmt.Sprintf("issuance failed at %s", cert.Status.LastFailureTime.UTC().Format(time.RFC3339)), true
+	}
+
+	var ready, issuing *certmanagerv1.CertificateCondition
+	for i := range cert.Status.Conditions {
+		switch cert.Status.Conditions[i].Type {
+		case certmanagerv1.CertificateConditionReady:
+			ready = &cert.Status.Conditions[i]
+		case certmanagerv1.CertificateConditionIssuing:
+			issuing = &cert.Status.Conditions[i]
+		}
+	}
+
+	if issuing != nil && issuing.Status == cmmeta.ConditionTrue {
+		if issuing.LastTransitionTime != nil && now.Sub(issuing.LastTransitionTime.Time) > renewalStuckAfter {
+			return fmt.Sprintf("issuance in progress since %s", issuing.LastTransitionTime.UTC().Format(time.RFC3339)), true
+		}
+		return "", false
+	}
+
+	if ready != nil && ready.Status == cmmeta.ConditionFalse {
+		return fmt.Sprintf("not ready: %s", ready.Reason), true
+	}
+
+	return "", false
+}
+
+// renewalObjects indexes the CertificateRequests, Orders and Challenges of a
+// namespace by the UID of their controller
+type renewalObjects struct {
+	requests   map[types.UID][]*certmanagerv1.CertificateRequest
+	orders     map[types.UID][]*cmacme.Order
+	challenges map[types.UID][]*cmacme.Challenge
+	err        error
+}
+
+// renewalIndex holds the renewal objects of the namespaces with failing
+// certificates, so each namespace is listed at most once per check
+type renewalIndex map[string]*renewalObjects
+
+// listRenewalObjects lists the renewal objects of a namespace on first use
+func (m *CertificateMonitor) listRenewalObjects(ctx context.Context, index renewalIndex, namespace string) *renewalObjects {
+	if objects, ok := index[namespace]; ok {
+		return objects
+	}
+
+	objects := &renewalObjects{
+		requests:   make(map[types.UID][]*certmanagerv1.CertificateRequest),
+		orders:     make(map[types.UID][]*cmacme.Order),
+		challenges: make(map[types.UID][]*cmacme.Challenge),
+	}
+	index[namespace] = objects
+
+	requests, err := m.client.CertmanagerV1().CertificateRequests(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		objects.err = fmt.Errorf("failed to list certificate requests: %w", err)
+		return objects
+	}
+	for i := range requests.Items {
+		if owner := metav1.GetControllerOf(&requests.Items[i]); owner != nil {
+			objects.requests[owner.UID] = append(objects.requests[owner.UID], &requests.Items[i])
+		}
+	}
+
+	orders, err := m.client.AcmeV1().Orders(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		objects.err = fmt.Errorf("failed to list orders: %w", err)
+		return objects
+	}
+	for i := range orders.Items {
+		if owner := metav1.GetControllerOf(&orders.Items[i]); owner != nil {
+			objects.orders[owner.UID] = append(objects.orders[owner.UID], &orders.Items[i])
+		}
+	}
+
+	challenges, err := m.client.AcmeV1().Challenges(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		objects.err = fmt.Errorf("failed to list challenges: %w", err)
+		return objects
+	}
+	for i := range challenges.Items {
+		if owner := metav1.GetControllerOf(&challenges.Items[i]); owner != nil {
+			objects.challenges[owner.UID] = append(objects.challenges[owner.UID], &challenges.Items[i])
+		}
+	}
+
+	return objects
+}
+
+// diagnoseRenewal follows a failing Certificate to its latest
+// CertificateRequest, Order and Challenges when enabled. It returns nil when
+// renewal diagnostics are disabled or the renewal looks healthy.
+func (m *CertificateMonitor) diagnoseRenewal(ctx context.Context, index renewalIndex, cert *certmanagerv1.Certificate, now time.Time) *webhook.RenewalDetails {
+	if !m.config.DiagnoseRenewals {
+		return nil
+	}
+
+	problem, failing := renewalProblem(cert, now)
+	if !failing {
+		return nil
+	}
+
+	renewal := &webhook.RenewalDetails{Problem: problem}
+	if err := describeRenewal(m.listRenewalObjects(ctx, index, cert.Namespace), cert, renewal); err != nil {
+		m.logger.WithError(err).WithField("certificate", cert.Name).Warn("Failed to diagnose certificate renewal")
+	}
+	return renewal
+}
+
+// describeRenewal fills in the resources cert-manager created for the latest
+// issuance, and returns the error listing them if they could not all be listed
+func describeRenewal(objects *renewalObjects, cert *certmanagerv1.Certificate, renewal *webhook.RenewalDetails) error {
+	var request *certmanagerv1.CertificateRequest
+	for _, candidate := range objects.requests[cert.UID] {
+		if request == nil || newerRequest(candidate, request) {
+			request = candidate
+		}
+	}
+	if request == nil {
+		return objects.err
+	}
+
+	renewal.CertificateRequest = certificateRequestStatus(request)
+
+	var order *cmacme.Order
+	for _, candidate := range objects.orders[request.UID] {
+		if order == nil || candidate.CreationTimestamp.After(order.CreationTimestamp.Time) {
+			order = candidate
+		}
+	}
+	if order == nil {
+		return objects.err
+	}
+
+	renewal.Order = &webhook.OrderStatus{
+		Name:      order.Name,
+		State:     string(order.Status.State),
+		Reason:    order.Status.Reason,
+		CreatedAt: order.CreationTimestamp.Time,
+	}
+
+	for _, challenge := range objects.challenges[order.UID] {
+		renewal.Challenges = append(renewal.Challenges, webhook.ChallengeStatus{
+			Name:       challenge.Name,
+			Type:       string(challenge.Spec.Type),
+			DNSName:    challenge.Spec.DNSName,
+			State:      string(challenge.Status.State),
+			Reason:     challenge.Status.Reason,
+			Presented:  challenge.Status.Presented,
+			Processing: challenge.Status.Processing,
+			CreatedAt:  challenge.CreationTimestamp.Time,
+		})
+	}
+
+	sort.Slice(renewal.Challenges, func(i, j int) bool {
+		return renewal.Challenges[i].DNSName < renewal.Challenges[j].DNSName
+	})
+
+	return objects.err
+}
+
+// newerRequest reports whether a CertificateRequest is for a later revision than another
+func newerRequest(a, b *certmanagerv1.CertificateRequest) bool {
+	revisionA, _ := strconv.Atoi(a.Annotations[certmanagerv1.CertificateRequestRevisionAnnotationKey])
+	revisionB, _ := strconv.Atoi(b.Annotations[certmanagerv1.CertificateRequestRevisionAnnotationKey])
+	if revisionA != revisionB {
+		return revisionA > revisionB
+	}
+	return a.CreationTimestamp.After(b.CreationTimestamp.Time)
+}
+
+// certificateRequestStatus reports the most relevant condition of a
+// CertificateRequest: a denial or invalid request, otherwise its Ready condition
+func certificateRequestStatus(request *certmanagerv1.CertificateRequest) *webhook.CertificateRequestStatus {
+	status := &webhook.CertificateRequestStatus{
+		Name:      request.Name,
+		CreatedAt: request.CreationTimestamp.Time,
+	}
+
+	var ready *certmanagerv1.CertificateRequestCondition
+	for i, condition := range request.Status.Conditions {
+		switch condition.Type {
+		case certmanagerv1.CertificateRequestConditionDenied, certmanagerv1.CertificateRequestConditionInvalidRequest:
+			if condition.Status == cmmeta.ConditionTrue {
+				status.Reason = string(condition.Type)
+				status.Message = condition.Message
+				return status
+			}
+		case certmanagerv1.CertificateRequestConditionReady:
+			ready = &request.Status.Conditions[i]
+		}
+	}
+
+	if ready != nil {
+		status.Reason = ready.Reason
+		status.Message = ready.Message
+	}
+	return status
+}
+
+// checkRenewal notifies when a Certificate is failing to renew
+func (m *CertificateMonitor) checkRenewal(ctx context.Context, cert *certmanagerv1.Certificate, renewal *webhook.RenewalDetails, now time.Time) error {
+	if renewal == nil {
+		return nil
+	}
+
+	certKey := notificationKey(ctx, cert)
+	if m.isSilenced(cert, now) || !m.shouldNotifyAlert(certKey, webhook.TypeRenewalFailed, m.repeatInterval(ctx), now) {
+		return nil
+	}
+
+	m.logger.WithField("certificate", cert.Name).WithField("problem", renewal.Summary()).Warn("Certificate is failing to renew")
+
+	var expiresAt time.Time
+	if cert.Status.NotAfter != nil {
+		expiresAt = cert.Status.NotAfter.Time
+	}
+
+	err := m.notifier.SendRenewalFailedNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, expiresAt, *renewal)
+	m.reportNotification(ctx, cert, webhook.TypeRenewalFailed, err, now)
+	if err != nil {
+		return fmt.Errorf("failed to send renewal failed notification: %w", err)
+	}
+
+	m.markAlertNotified(certKey, webhook.TypeRenewalFailed, now)
+	return nil
+}
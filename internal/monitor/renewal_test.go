@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	certmanagerfake "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+func TestCheckCertificates_RenewalFailed(t *testing.T) {
+	now := time.Now()
+	controlledBy := func(owner metav1.Object, kind string) []metav1.OwnerReference {
+		controller := true
+		return []metav1.OwnerReference{{Kind: kind, Name: owner.GetName(), UID: owner.GetUID(), Controller: &controller}}
+	}
+
+	cert := newTestCertificate("web", now.Add(90*24*time.Hour))
+	cert.UID = "certificate-uid"
+	cert.Status.LastFailureTime = &metav1.Time{Time: now.Add(-time.Hour)}
+
+	// A second failing certificate in the namespace without any requests
+	api := newTestCertificate("api", now.Add(90*24*time.Hour))
+	api.UID = "api-uid"
+	api.Status.LastFailureTime = &metav1.Time{Time: now.Add(-time.Hour)}
+
+	oldRequest := &certmanagerv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "web-1", Namespace: "default", UID: "old-request-uid",
+			Annotations:     map[string]string{certmanagerv1.CertificateRequestRevisionAnnotationKey: "1"},
+			OwnerReferences: controlledBy(cert, "Certificate"),
+		},
+	}
+	request := &certmanagerv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "web-2", Namespace: "default", UID: "request-uid",
+			Annotations:     map[string]string{certmanagerv1.CertificateRequestRevisionAnnotationKey: "2"},
+			OwnerReferences: controlledBy(cert, "Certificate"),
+		},
+		Status: certmanagerv1.CertificateRequestStatus{Conditions: []certmanagerv1.CertificateRequestCondition{
+			{Type: certmanagerv1.CertificateRequestConditionReady, Status: cmmeta.ConditionFalse, Reason: "Pending", Message: "Waiting on certificate issuance from order default/web-2-1234"},
+		}},
+	}
+	order := &cmacme.Order{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-2-1234", Namespace: "default", UID: "order-uid", OwnerReferences: controlledBy(request, "CertificateRequest")},
+		Status:     cmacme.OrderStatus{State: cmacme.Pending},
+	}
+	challenge := &cmacme.Challenge{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-2-1234-5678", Namespace: "default", OwnerReferences: controlledBy(order, "Order")},
+		Spec:       cmacme.ChallengeSpec{Type: cmacme.ACMEChallengeTypeHTTP01, DNSName: "web.example.com"},
+		Status: cmacme.ChallengeStatus{
+			State:     cmacme.Pending,
+			Presented: true,
+			Reason:    "Waiting for HTTP-01 challenge propagation: wrong status code '404', expected '200'",
+		},
+	}
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{DiagnoseRenewals: true},
+		[]runtime.Object{cert, api, oldRequest, request, order, challenge}, nil)
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// The namespace is listed once for both failing certificates
+	lists := map[string]int{}
+	for _, action := range certMonitor.client.(*certmanagerfake.Clientset).Actions() {
+		if action.GetVerb() == "list" {
+			lists[action.GetResource().Resource]++
+		}
+	}
+	if lists["certificaterequests"] != 1 || lists["orders"] != 1 || lists["challenges"] != 1 {
+		t.Errorf("Expected the renewal objects to be listed once, got %v", lists)
+	}
+
+	payloads := map[string]webhook.NotificationPayload{}
+	for _, payload := range recorder.received() {
+		if payload.Type == webhook.TypeRenewalFailed {
+			payloads[payload.Certificate.Name] = payload
+		}
+	}
+	if len(payloads) != 2 {
+		t.Fatalf("Expected renewal_failed notifications for both certificates, got %v", recorder.types())
+	}
+
+	payload := payloads["web"]
+	expected := "Certificate default/web is failing to renew: HTTP-01 challenge for web.example.com is pending: Waiting for HTTP-01 challenge propagation: wrong status code '404', expected '200'"
+	if payload.Message != expected {
+		t.Errorf("Expected message %q, got %q", expected, payload.Message)
+	}
+
+	if payload.Renewal == nil || payload.Renewal.CertificateRequest == nil || payload.Renewal.CertificateRequest.Name != "web-2" {
+		t.Errorf("Expected the latest certificate request, got %+v", payload.Renewal)
+	}
+
+	if payload.Renewal != nil && (payload.Renewal.Order == nil || len(payload.Renewal.Challenges) != 1) {
+		t.Errorf("Expected the order and its challenge, got %+v", payload.Renewal)
+	}
+
+	statuses := certMonitor.Certificates()
+	if len(statuses) != 2 || statuses[0].Renewal == nil || statuses[1].Renewal == nil {
+		t.Errorf("Expected the status to include renewal details, got %+v", statuses)
+	}
+}
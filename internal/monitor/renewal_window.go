@@ -0,0 +1,128 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// renewalGracePeriod is how long after its renewal time a certificate is
+// given to renew before it is considered overdue
+const renewalGracePeriod = time.Hour
+
+// renewalWindow is a Certificate's effective renewal schedule
+type renewalWindow struct {
+	renewalTime time.Time
+	duration    time.Duration
+	renewBefore time.Duration
+
+	// configured is set when renewBefore or renewBeforePercentage is set
+	configured bool
+}
+
+// effectiveRenewal computes when cert-manager renews the certificate: its
+// status renewal time if set, otherwise derived from the duration and
+// renewBefore the same way cert-manager does
+func effectiveRenewal(cert *certmanagerv1.Certificate) (renewalWindow, bool) {
+	if cert.Status.NotAfter == nil {
+		return renewalWindow{}, false
+	}
+	notAfter := cert.Status.NotAfter.Time
+
+	window := renewalWindow{duration: defaultCertificateDuration}
+	switch {
+	case cert.Spec.Duration != nil:
+		window.duration = cert.Spec.Duration.Duration
+	case cert.Status.NotBefore != nil:
+		window.duration = notAfter.Sub(cert.Status.NotBefore.Time)
+	}
+
+	// cert-manager renews after two thirds of the duration by default
+	window.renewBefore = window.duration / 3
+	switch {
+	case cert.Spec.RenewBefore != nil:
+		window.renewBefore = cert.Spec.RenewBefore.Duration
+		window.configured = true
+	case cert.Spec.RenewBeforePercentage != nil:
+		window.renewBefore = window.duration * time.Duration(*cert.Spec.RenewBeforePercentage) / 100
+		window.configured = true
+	}
+
+	if cert.Status.RenewalTime != nil {
+		window.renewalTime = cert.Status.RenewalTime.Time
+	} else if window.renewBefore < window.duration {
+		window.renewalTime = notAfter.Add(-window.renewBefore)
+	} else {
+		// cert-manager falls back to the default when renewBefore is too long
+		window.renewalTime = notAfter.Add(-window.duration / 3)
+	}
+
+	return window, true
+}
+
+// isExpiryDue reports whether an expiring certificate should be reported.
+// cert-manager renews on its own schedule, so only once renewal is overdue.
+// Renewal does not help when the chain limits the expiry, and is not
+// scheduled for an issued certificate expiring before its status claims.
+func isExpiryDue(cert *certmanagerv1.Certificate, inspection *secretInspection, now time.Time) bool {
+	limitedByChain := inspection != nil && inspection.limitedBy != nil
+	expiresAt, ok := effectiveExpiry(cert, inspection)
+	staleStatus := ok && cert.Status.NotAfter != nil && expiresAt.Before(cert.Status.NotAfter.Time)
+	return limitedByChain || staleStatus || isRenewalOverdue(cert, now)
+}
+
+// isRenewalOverdue reports whether the certificate should have been renewed
+// by now. Certificates without a known renewal time are always overdue.
+func isRenewalOverdue(cert *certmanagerv1.Certificate, now time.Time) bool {
+	window, ok := effectiveRenewal(cert)
+	if !ok {
+		return true
+	}
+	return now.After(window.renewalTime.Add(renewalGracePeriod))
+}
+
+// renewalAdvisories describes problems with a Certificate's configured
+// renewal window for an expiration threshold
+func (m *CertificateMonitor) renewalAdvisories(cert *certmanagerv1.Certificate, threshold time.Duration) []string {
+	window, ok := effectiveRenewal(cert)
+	if !ok || !window.configured {
+		return nil
+	}
+
+	var advisories []string
+	if window.renewBefore >= window.duration {
+		advisories = append(advisories, fmt.Sprintf("renewBefore %s is not shorter than the duration %s, so cert-manager ignores it and renews after two thirds of the duration", window.renewBefore, window.duration))
+	} else if window.renewBefore < threshold {
+		advisories = append(advisories, fmt.Sprintf("renewBefore %s is shorter than the expiration threshold %s, so expiring notifications are deferred until renewal is overdue", window.renewBefore, threshold))
+	}
+
+	return advisories
+}
+
+// checkRenewalWindow sends an advisory when a Certificate's renewal window is misconfigured
+func (m *CertificateMonitor) checkRenewalWindow(ctx context.Context, cert *certmanagerv1.Certificate, now time.Time) error {
+	advisories := m.renewalAdvisories(cert, m.expirationThreshold(ctx))
+	if len(advisories) == 0 {
+		return nil
+	}
+
+	certKey := notificationKey(ctx, cert)
+	if m.isSilenced(cert, now) || !m.shouldNotifyAlert(certKey, webhook.TypeRenewalWindowMisconfigured, m.repeatInterval(ctx), now) {
+		return nil
+	}
+
+	m.logger.WithField("certificate", cert.Name).WithField("advisories", advisories).Info("Certificate renewal window is misconfigured")
+
+	err := m.notifier.SendRenewalWindowNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, cert.Status.NotAfter.Time, advisories)
+	m.reportNotification(ctx, cert, webhook.TypeRenewalWindowMisconfigured, err, now)
+	if err != nil {
+		return fmt.Errorf("failed to send renewal window notification: %w", err)
+	}
+
+	m.markAlertNotified(certKey, webhook.TypeRenewalWindowMisconfigured, now)
+	return nil
+}
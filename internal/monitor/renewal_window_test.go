@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+func TestCheckCertificates_RenewalWindow(t *testing.T) {
+	now := time.Now()
+
+	// Expiring within the threshold, but cert-manager renews it a week before expiry
+	scheduled := newTestCertificate("scheduled", now.Add(20*24*time.Hour))
+	scheduled.Spec.RenewBefore = &metav1.Duration{Duration: 7 * 24 * time.Hour}
+
+	// Expiring within the threshold and past its renewal time
+	overdue := newTestCertificate("overdue", now.Add(10*24*time.Hour))
+	overdue.Status.RenewalTime = &metav1.Time{Time: now.Add(-24 * time.Hour)}
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{}, []runtime.Object{scheduled, overdue}, nil)
+
+	summary, err := certMonitor.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if summary.Expiring != 1 {
+		t.Errorf("Expected only the overdue certificate to count as expiring, got %d", summary.Expiring)
+	}
+
+	got := make(map[string]webhook.NotificationPayload)
+	for _, payload := range recorder.received() {
+		got[payload.Certificate.Name+"/"+payload.Type] = payload
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 notifications, got %v", recorder.types())
+	}
+	if _, ok := got["overdue/expiring"]; !ok {
+		t.Errorf("Expected an expiring notification for the overdue certificate, got %v", recorder.types())
+	}
+	advisory, ok := got["scheduled/"+webhook.TypeRenewalWindowMisconfigured]
+	if !ok {
+		t.Fatalf("Expected a renewal window advisory for the scheduled certificate, got %v", recorder.types())
+	}
+	if len(advisory.Details) != 1 || !strings.Contains(advisory.Details[0], "shorter than the expiration threshold") {
+		t.Errorf("Unexpected advisory details: %v", advisory.Details)
+	}
+
+	for _, status := range certMonitor.Certificates() {
+		if status.RenewalTime == nil {
+			t.Errorf("Expected a renewal time for %s", status.Name)
+		}
+		if status.Name == "scheduled" && status.State != StateOK {
+			t.Errorf("Expected the scheduled certificate to be reported as ok until it is due, got %s", status.State)
+		}
+		if status.Name == "overdue" && status.State != StateExpiring {
+			t.Errorf("Expected the overdue certificate to be reported as expiring, got %s", status.State)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/certinspect"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// caCertKey is the Secret key cert-manager stores the issuing CA in
+const caCertKey = "ca.crt"
+
+// secretInspection is the result of reading a Certificate's TLS Secret
+type secretInspection struct {
+	chain      []*x509.Certificate
+	caChain    []*x509.Certificate
+	mismatches []string
+
+	// notAfter is the earliest expiry in the chain, if the issued
+	// certificate could be parsed
+	notAfter *time.Time
+
+	// limitedBy is the intermediate or CA certificate that expires before
+	// the leaf, if any
+	limitedBy *certinspect.ChainElement
+}
+
+// leaf returns the leaf certificate from the Secret, if it could be parsed
+func (i *secretInspection) leaf() *x509.Certificate {
+	if i == nil || len(i.chain) == 0 {
+		return nil
+	}
+	return i.chain[0]
+}
+
+// prepareCertificate inspects the certificate's Secret when enabled and, if
+// the issued certificate could be parsed, records the earliest expiry in its
+// chain, which expiry is then evaluated against rather than the possibly
+// stale Certificate status. It returns nil when secret inspection is
+// disabled or the Secret could not be read.
+func (m *CertificateMonitor) prepareCertificate(ctx context.Context, cert *certmanagerv1.Certificate) *secretInspection {
+	if !m.config.InspectSecrets {
+		return nil
+	}
+
+	inspection, err := m.inspectSecret(ctx, cert)
+	if err != nil {
+		m.logger.WithError(err).WithField("certificate", cert.Name).Warn("Failed to inspect certificate secret")
+		return nil
+	}
+
+	if earliest, ok := certinspect.Earliest(certinspect.Chain(inspection.chain, inspection.caChain)); ok {
+		notAfter := earliest.Certificate.NotAfter
+		inspection.notAfter = &notAfter
+		if earliest.Role != certinspect.RoleLeaf {
+			inspection.limitedBy = &earliest
+		}
+	}
+
+	return inspection
+}
+
+// effectiveExpiry returns when a certificate effectively expires: the
+// earliest expiry in its chain if the Secret was inspected, otherwise the
+// expiry in its status. The Certificate itself reports its own expiry.
+func effectiveExpiry(cert *certmanagerv1.Certificate, inspection *secretInspection) (time.Time, bool) {
+	if inspection != nil && inspection.notAfter != nil {
+		return *inspection.notAfter, true
+	}
+	if cert.Status.NotAfter == nil {
+		return time.Time{}, false
+	}
+	return cert.Status.NotAfter.Time, true
+}
+
+// inspectSecret reads and parses the certificate's TLS Secret and compares it
+// against the Certificate
+func (m *CertificateMonitor) inspectSecret(ctx context.Context, cert *certmanagerv1.Certificate) (*secretInspection, error) {
+	secretKey := fmt.Sprintf("%s/%s", cert.Namespace, cert.Spec.SecretName)
+
+	secret, err := m.kubeClient.CoreV1().Secrets(cert.Namespace).Get(ctx, cert.Spec.SecretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// Certificates that have never been issued have no Secret yet
+		if cert.Status.NotAfter == nil {
+			return &secretInspection{}, nil
+		}
+		return &secretInspection{mismatches: []string{fmt.Sprintf("Secret %s not found", secretKey)}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", secretKey, err)
+	}
+
+	data := secret.Data[corev1.TLSCertKey]
+	if len(data) == 0 {
+		return &secretInspection{mismatches: []string{fmt.Sprintf("Secret %s has no %s", secretKey, corev1.TLSCertKey)}}, nil
+	}
+
+	chain, err := certinspect.ParseCertificates(data)
+	if err != nil {
+		return &secretInspection{mismatches: []string{fmt.Sprintf("Secret %s has an invalid %s: %v", secretKey, corev1.TLSCertKey, err)}}, nil
+	}
+
+	expected := certinspect.Expected{
+		CommonName:     cert.Spec.CommonName,
+		DNSNames:       cert.Spec.DNSNames,
+		IPAddresses:    cert.Spec.IPAddresses,
+		URIs:           cert.Spec.URIs,
+		EmailAddresses: cert.Spec.EmailAddresses,
+	}
+	if cert.Status.NotAfter != nil {
+		notAfter := cert.Status.NotAfter.Time
+		expected.NotAfter = &notAfter
+	}
+
+	inspection := &secretInspection{
+		chain:      chain,
+		mismatches: certinspect.Compare(expected, chain[0]),
+	}
+
+	// ca.crt is optional, but an unparseable one hides the CA's expiry
+	if caData := secret.Data[caCertKey]; len(caData) > 0 {
+		caChain, err := certinspect.ParseCertificates(caData)
+		if err != nil {
+			inspection.mismatches = append(inspection.mismatches, fmt.Sprintf("Secret %s has an invalid %s: %v", secretKey, caCertKey, err))
+		} else {
+			inspection.caChain = caChain
+		}
+	}
+
+	return inspection, nil
+}
+
+// chainElementPayload describes a chain element for a notification
+func chainElementPayload(element certinspect.ChainElement) webhook.ChainElement {
+	return webhook.ChainElement{
+		Description:  element.Describe(),
+		Role:         element.Role,
+		Source:       element.Source,
+		Position:     element.Position,
+		Subject:      element.Certificate.Subject.String(),
+		Issuer:       element.Certificate.Issuer.String(),
+		NotAfter:     element.Certificate.NotAfter,
+		SerialNumber: certinspect.SerialNumber(element.Certificate),
+	}
+}
+
+// checkSecretMismatch notifies when the Secret disagrees with the Certificate
+func (m *CertificateMonitor) checkSecretMismatch(ctx context.Context, cert *certmanagerv1.Certificate, inspection *secretInspection, now time.Time) error {
+	if inspection == nil || len(inspection.mismatches) == 0 {
+		return nil
+	}
+
+	certKey := notificationKey(ctx, cert)
+	if m.isSilenced(cert, now) || !m.shouldNotifyAlert(certKey, webhook.TypeSecretMismatch, m.repeatInterval(ctx), now) {
+		return nil
+	}
+
+	m.logger.WithField("certificate", cert.Name).WithField("mismatches", inspection.mismatches).Warn("Certificate secret does not match certificate")
+
+	var expiresAt time.Time
+	var serialNumber string
+	if leaf := inspection.leaf(); leaf != nil {
+		expiresAt = leaf.NotAfter
+		serialNumber = certinspect.SerialNumber(leaf)
+	}
+
+	err := m.notifier.SendSecretMismatchNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, expiresAt, serialNumber, inspection.mismatches)
+	m.reportNotification(ctx, cert, webhook.TypeSecretMismatch, err, now)
+	if err != nil {
+		return fmt.Errorf("failed to send secret mismatch notification: %w", err)
+	}
+
+	m.markAlertNotified(certKey, webhook.TypeSecretMismatch, now)
+	return nil
+}
@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/certtest"
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+func TestCheckCertificates_SecretMismatch(t *testing.T) {
+	now := time.Now()
+	cert := newTestCertificate("web", now.Add(60*24*time.Hour))
+	missing := newTestCertificate("missing", now.Add(60*24*time.Hour))
+
+	// The Secret holds a certificate without the requested DNS name that
+	// expires much earlier than the Certificate status claims
+	secret := newTestSecret(t, "web-tls", []string{"other.example.com"}, now.Add(5*24*time.Hour))
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{InspectSecrets: true}, []runtime.Object{cert, missing}, []runtime.Object{secret})
+
+	summary, err := certMonitor.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if summary.Expiring != 1 {
+		t.Errorf("Expected expiry to be evaluated against the Secret, got %+v", summary)
+	}
+
+	types := recorder.types()
+	counts := map[string]int{}
+	for _, notificationType := range types {
+		counts[notificationType]++
+	}
+
+	if counts["expiring"] != 1 || counts[webhook.TypeSecretMismatch] != 2 {
+		t.Errorf("Expected 1 expiring and 2 secret_mismatch notifications, got %v", types)
+	}
+
+	for _, status := range certMonitor.Certificates() {
+		if len(status.Mismatches) == 0 {
+			t.Errorf("Expected mismatches for %s", status.Name)
+		}
+	}
+
+	// The Certificate keeps reporting its own expiry
+	if !cert.Status.NotAfter.Time.After(now.Add(59 * 24 * time.Hour)) {
+		t.Errorf("Expected the Certificate status to be left as listed, got %v", cert.Status.NotAfter)
+	}
+}
+
+func TestCheckCertificates_IndependentChecks(t *testing.T) {
+	now := time.Now()
+	cert := newTestCertificate("web", now.Add(10*24*time.Hour))
+	secret := newTestSecret(t, "web-tls", []string{"other.example.com"}, now.Add(10*24*time.Hour))
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{InspectSecrets: true}, []runtime.Object{cert}, []runtime.Object{secret})
+	recorder.fail("expiring")
+
+	summary, err := certMonitor.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if summary.Failed != 1 {
+		t.Errorf("Expected the failed delivery to be counted, got %+v", summary)
+	}
+
+	// The expiry alert failing must not hold back the mismatch alert
+	if types := recorder.types(); len(types) != 1 || types[0] != webhook.TypeSecretMismatch {
+		t.Errorf("Expected a secret_mismatch notification, got %v", types)
+	}
+}
+
+func TestCheckCertificates_ChainExpiry(t *testing.T) {
+	now := time.Now()
+	cert := newTestCertificate("web", now.Add(60*24*time.Hour))
+
+	ca := func(serial int64, commonName string, notAfter time.Time) *x509.Certificate {
+		return &x509.Certificate{
+			SerialNumber:          big.NewInt(serial),
+			Subject:               pkix.Name{CommonName: commonName},
+			NotBefore:             now.Add(-time.Hour),
+			NotAfter:              notAfter,
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+	}
+
+	root, rootKey, rootPEM := certtest.Issue(t, ca(1, "Internal Root CA", now.Add(3650*24*time.Hour)), nil, nil)
+	intermediate, intermediateKey, intermediatePEM := certtest.Issue(t, ca(2, "Internal Intermediate CA", now.Add(5*24*time.Hour)), root, rootKey)
+	_, _, leafPEM := certtest.Issue(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "web.example.com"},
+		DNSNames:     []string{"web.example.com"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     cert.Status.NotAfter.Time,
+	}, intermediate, intermediateKey)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "default"},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey: append(leafPEM, intermediatePEM...),
+			caCertKey:         rootPEM,
+		},
+	}
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{InspectSecrets: true}, []runtime.Object{cert}, []runtime.Object{secret})
+
+	summary, err := certMonitor.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if summary.Expiring != 1 {
+		t.Errorf("Expected expiry to be evaluated against the intermediate, got %+v", summary)
+	}
+
+	payloads := recorder.received()
+	if len(payloads) != 1 {
+		t.Fatalf("Expected 1 notification, got %v", recorder.types())
+	}
+
+	payload := payloads[0]
+	if payload.Type != "expiring" || payload.ChainElement == nil {
+		t.Fatalf("Expected an expiring notification naming a chain element, got %+v", payload)
+	}
+
+	if payload.ChainElement.Role != "intermediate" || payload.ChainElement.Position != 2 {
+		t.Errorf("Expected the intermediate at tls.crt #2, got %+v", payload.ChainElement)
+	}
+
+	statuses := certMonitor.Certificates()
+	if len(statuses) != 1 || statuses[0].LimitedBy != `intermediate "Internal Intermediate CA" (tls.crt #2)` {
+		t.Fatalf("Expected the status to name the intermediate, got %+v", statuses)
+	}
+
+	// The status and renewal time report the Certificate's own expiry, not
+	// the intermediate's
+	if statuses[0].State != StateExpiring || statuses[0].ExpiresAt == nil || !statuses[0].ExpiresAt.Equal(cert.Status.NotAfter.Time) {
+		t.Errorf("Expected an expiring status with the Certificate's own expiry, got %+v", statuses[0])
+	}
+	if statuses[0].RenewalTime == nil || !statuses[0].RenewalTime.After(now.Add(29*24*time.Hour)) {
+		t.Errorf("Expected the renewal time to follow the Certificate's own expiry, got %v", statuses[0].RenewalTime)
+	}
+}
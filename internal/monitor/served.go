@@ -0,0 +1,139 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/certinspect"
+	"github.com/wiruzman/cert-manager-notifier/internal/probe"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// AnnotationProbeEndpoints lists comma-separated host:port endpoints to probe
+// for a Certificate instead of its DNS names. Hosts other than the
+// Certificate's own names must be allowed by PROBE_ALLOWED_ENDPOINTS.
+const AnnotationProbeEndpoints = "cert-manager-notifier.io/probe-endpoints"
+
+// probeCertificate performs a TLS handshake with each of the certificate's
+// endpoints when enabled and compares the served certificate with the one
+// issued into the Secret. The endpoints are probed concurrently and within
+// the probe timeout altogether. It also returns the serial number of the
+// issued certificate, if known.
+func (m *CertificateMonitor) probeCertificate(ctx context.Context, cert *certmanagerv1.Certificate, inspection *secretInspection) ([]webhook.EndpointStatus, string) {
+	if !m.config.ProbeEndpoints {
+		return nil, ""
+	}
+
+	issued := inspection.leaf()
+	if issued == nil && inspection == nil {
+		secretInspection, err := m.inspectSecret(ctx, cert)
+		if err != nil {
+			m.logger.WithError(err).WithField("certificate", cert.Name).Debug("Failed to read issued certificate for probing")
+		} else {
+			issued = secretInspection.leaf()
+		}
+	}
+
+	// The allowlist is checked when the configuration is loaded
+	allowlist, _ := probe.NewAllowlist(m.config.ProbeAllowedEndpoints)
+	targets, rejected := probe.Endpoints(cert.Annotations[AnnotationProbeEndpoints], cert.Spec.DNSNames, cert.Spec.IPAddresses, allowlist)
+
+	var endpoints []webhook.EndpointStatus
+	for _, address := range rejected {
+		m.logger.WithField("certificate", cert.Name).WithField("endpoint", address).Warn("Probe endpoint is not allowed")
+		endpoints = append(endpoints, webhook.EndpointStatus{
+			Address: address,
+			Error:   "not probed: the host is not a name of the certificate or in PROBE_ALLOWED_ENDPOINTS",
+		})
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, m.config.ProbeTimeout)
+	defer cancel()
+
+	for _, result := range probe.Probe(probeCtx, targets, m.config.ProbeTimeout, m.config.ProbeConcurrency) {
+		status := webhook.EndpointStatus{Address: result.Endpoint.Address, ServerName: result.Endpoint.ServerName}
+		if result.Err != nil {
+			m.logger.WithError(result.Err).WithField("certificate", cert.Name).WithField("endpoint", result.Endpoint.Address).Debug("Failed to probe endpoint")
+			status.Error = result.Err.Error()
+			endpoints = append(endpoints, status)
+			continue
+		}
+
+		served := result.Certificate
+		expiresAt := served.NotAfter
+		status.SerialNumber = certinspect.SerialNumber(served)
+		status.ExpiresAt = &expiresAt
+		status.Stale = issued != nil && served.SerialNumber.Cmp(issued.SerialNumber) != 0
+		endpoints = append(endpoints, status)
+	}
+
+	if issued == nil {
+		return endpoints, ""
+	}
+	return endpoints, certinspect.SerialNumber(issued)
+}
+
+// checkServedCertificate notifies when endpoints serve a stale certificate or
+// one that is expired or expiring soon
+func (m *CertificateMonitor) checkServedCertificate(ctx context.Context, cert *certmanagerv1.Certificate, inspection *secretInspection, endpoints []webhook.EndpointStatus, issuedSerial string, now time.Time) error {
+	threshold := m.expirationThreshold(ctx)
+
+	var stale, expiring []webhook.EndpointStatus
+	for _, endpoint := range endpoints {
+		if endpoint.Stale {
+			stale = append(stale, endpoint)
+		}
+		if endpoint.ExpiresAt != nil && endpoint.ExpiresAt.Sub(now) <= threshold {
+			expiring = append(expiring, endpoint)
+		}
+	}
+
+	// An expiring issued certificate is already reported as expiring or
+	// expired, or renews on schedule, so served_expiring only covers
+	// endpoints lagging behind
+	if m.isCertificateExpired(cert, inspection, now) || m.isCertificateExpiring(cert, inspection, threshold, now) {
+		expiring = nil
+	}
+
+	if len(stale) == 0 && len(expiring) == 0 {
+		return nil
+	}
+
+	if m.isSilenced(cert, now) {
+		return nil
+	}
+
+	certKey := notificationKey(ctx, cert)
+
+	var expiresAt time.Time
+	if cert.Status.NotAfter != nil {
+		expiresAt = cert.Status.NotAfter.Time
+	}
+
+	if len(stale) > 0 && m.shouldNotifyAlert(certKey, webhook.TypeServedStale, m.repeatInterval(ctx), now) {
+		m.logger.WithField("certificate", cert.Name).WithField("endpoints", len(stale)).Warn("Endpoints serve a stale certificate")
+
+		err := m.notifier.SendServedStaleNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, expiresAt, issuedSerial, stale)
+		m.reportNotification(ctx, cert, webhook.TypeServedStale, err, now)
+		if err != nil {
+			return fmt.Errorf("failed to send served stale notification: %w", err)
+		}
+		m.markAlertNotified(certKey, webhook.TypeServedStale, now)
+	}
+
+	if len(expiring) > 0 && m.shouldNotifyAlert(certKey, webhook.TypeServedExpiring, m.repeatInterval(ctx), now) {
+		m.logger.WithField("certificate", cert.Name).WithField("endpoints", len(expiring)).Warn("Endpoints serve a certificate expiring soon")
+
+		err := m.notifier.SendServedExpiringNotification(ctx, cert.Name, cert.Namespace, m.getIssuerName(cert), cert.Spec.DNSNames, expiresAt, issuedSerial, expiring)
+		m.reportNotification(ctx, cert, webhook.TypeServedExpiring, err, now)
+		if err != nil {
+			return fmt.Errorf("failed to send served expiring notification: %w", err)
+		}
+		m.markAlertNotified(certKey, webhook.TypeServedExpiring, now)
+	}
+
+	return nil
+}
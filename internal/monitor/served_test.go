@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/certtest"
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+func TestCheckCertificates_ServedCertificate(t *testing.T) {
+	now := time.Now()
+
+	// The endpoint still serves the previous certificate, which expires soon
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "web.example.com"},
+		DNSNames:     []string{"web.example.com"},
+		NotBefore:    now.Add(-90 * 24 * time.Hour),
+		NotAfter:     now.Add(5 * 24 * time.Hour),
+	}
+	served, key, _ := certtest.Issue(t, template, nil, nil)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{served.Raw}, PrivateKey: key}}}
+	server.StartTLS()
+	defer server.Close()
+
+	cert := newTestCertificate("web", now.Add(60*24*time.Hour))
+	cert.Annotations = map[string]string{AnnotationProbeEndpoints: strings.TrimPrefix(server.URL, "https://") + ",169.254.169.254:80"}
+	secret := newTestSecret(t, "web-tls", []string{"web.example.com"}, now.Add(60*24*time.Hour))
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{ProbeEndpoints: true, ProbeTimeout: 5 * time.Second, ProbeAllowedEndpoints: []string{"127.0.0.1"}},
+		[]runtime.Object{cert}, []runtime.Object{secret})
+
+	if _, err := certMonitor.CheckOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	types := recorder.types()
+	if len(types) != 2 || types[0] != webhook.TypeServedStale || types[1] != webhook.TypeServedExpiring {
+		t.Fatalf("Expected served_stale and served_expiring notifications, got %v", types)
+	}
+
+	payload := recorder.received()[0]
+	if len(payload.Endpoints) != 1 || payload.Endpoints[0].SerialNumber != "2A" || payload.Certificate.SerialNumber != "01" {
+		t.Errorf("Expected the served and issued serial numbers, got %+v", payload)
+	}
+
+	// Endpoints outside the allowlist are reported without being probed
+	statuses := certMonitor.Certificates()
+	if len(statuses) != 1 || len(statuses[0].Endpoints) != 2 {
+		t.Fatalf("Expected the status to include both endpoints, got %+v", statuses)
+	}
+	if rejected := statuses[0].Endpoints[0]; rejected.Address != "169.254.169.254:80" || rejected.Error == "" {
+		t.Errorf("Expected the endpoint outside the allowlist to be rejected, got %+v", rejected)
+	}
+	if !statuses[0].Endpoints[1].Stale {
+		t.Errorf("Expected the status to include the stale endpoint, got %+v", statuses[0].Endpoints[1])
+	}
+}
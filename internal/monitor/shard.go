@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// owns reports whether this replica is responsible for a namespace of the
+// monitored cluster. Cluster-scoped resources belong to the empty namespace.
+// Without sharding every namespace is owned.
+func (m *CertificateMonitor) owns(namespace string) bool {
+	if m.shard == nil {
+		return true
+	}
+	return m.shard.Owns(m.cluster + "/" + namespace)
+}
+
+// shardOwner returns the replica responsible for a namespace of the
+// monitored cluster, or nothing without sharding
+func (m *CertificateMonitor) shardOwner(namespace string) string {
+	if m.shard == nil {
+		return ""
+	}
+	return m.shard.Owner(m.cluster + "/" + namespace)
+}
+
+// otherShardStatuses returns the view of the certificates other replicas
+// check, so that every replica serves the whole fleet. They are built from
+// the listed Certificates only: the details gathered while checking, such as
+// Secret mismatches, probed endpoints and renewal diagnostics, are only in
+// the view of the owning replica.
+func (m *CertificateMonitor) otherShardStatuses(certificates []certmanagerv1.Certificate, now time.Time) []CertificateStatus {
+	if m.shard == nil {
+		return nil
+	}
+
+	var statuses []CertificateStatus
+	for i := range certificates {
+		cert := &certificates[i]
+		if m.owns(cert.Namespace) {
+			continue
+		}
+		status := m.certificateStatus(cert, nil, nil, now)
+		status.Shard = m.shardOwner(cert.Namespace)
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// ownedCertificates returns the certificates in namespaces this replica owns
+func (m *CertificateMonitor) ownedCertificates(certificates []certmanagerv1.Certificate) []certmanagerv1.Certificate {
+	if m.shard == nil {
+		return certificates
+	}
+
+	owned := make([]certmanagerv1.Certificate, 0, len(certificates))
+	for _, cert := range certificates {
+		if m.owns(cert.Namespace) {
+			owned = append(owned, cert)
+		}
+	}
+	return owned
+}
@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/shard"
+)
+
+func TestCheckCertificates_Sharding(t *testing.T) {
+	now := time.Now()
+
+	var certificates []runtime.Object
+	for i := 0; i < 20; i++ {
+		cert := newTestCertificate("web", now.Add(-time.Hour))
+		cert.Namespace = fmt.Sprintf("team-%d", i)
+		certificates = append(certificates, cert)
+	}
+
+	certMonitor, recorder := newTestMonitor(t, &config.Config{}, certificates, nil)
+
+	leases := kubefake.NewSimpleClientset()
+	logger := logrus.NewEntry(logrus.New())
+	coordinator := shard.NewCoordinator(leases, "default", "notifier", "replica-1", time.Minute, logger)
+	other := shard.NewCoordinator(leases, "default", "notifier", "replica-2", time.Minute, logger)
+	for _, c := range []*shard.Coordinator{coordinator, other, coordinator} {
+		if err := c.Sync(context.Background()); err != nil {
+			t.Fatalf("Failed to sync shard leases: %v", err)
+		}
+	}
+	certMonitor.shard = coordinator
+
+	summary, err := certMonitor.CheckOnce(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if summary.Total == 0 || summary.Total == len(certificates) {
+		t.Fatalf("Expected the replica to own some but not all namespaces, got %d", summary.Total)
+	}
+	payloads := recorder.received()
+	if len(payloads) != summary.Total {
+		t.Errorf("Expected %d notifications, got %d", summary.Total, len(payloads))
+	}
+
+	for _, payload := range payloads {
+		if !coordinator.Owns("/" + payload.Certificate.Namespace) {
+			t.Errorf("Expected no notification for namespace %s owned by the other replica", payload.Certificate.Namespace)
+		}
+	}
+
+	// The view covers the whole fleet and names the replica checking each certificate
+	statuses := certMonitor.Certificates()
+	if len(statuses) != len(certificates) {
+		t.Fatalf("Expected all %d certificates in the view, got %d", len(certificates), len(statuses))
+	}
+	for _, status := range statuses {
+		if expected := coordinator.Owner("/" + status.Namespace); status.Shard != expected || status.State != StateExpired {
+			t.Errorf("Expected %s to be expired and checked by %s, got %+v", status.Namespace, expected, status)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/webhook"
+)
+
+// Certificate states reported in the monitor's view
+const (
+	StateOK       = "ok"
+	StateExpiring = "expiring"
+	StateExpired  = "expired"
+	StateUnknown  = "unknown"
+)
+
+// CertificateStatus is the monitor's view of a certificate as of the last check
+type CertificateStatus struct {
+	Cluster              string                   `json:"cluster,omitempty"`
+	Namespace            string                   `json:"namespace"`
+	Name                 string                   `json:"name"`
+	Issuer               string                   `json:"issuer"`
+	DNSNames             []string                 `json:"dns_names"`
+	ExpiresAt            *time.Time               `json:"expires_at,omitempty"`
+	Ready                bool                     `json:"ready"`
+	State                string                   `json:"state"`
+	Silenced             bool                     `json:"silenced"`
+	Mismatches           []string                 `json:"secret_mismatches,omitempty"`
+	LimitedBy            string                   `json:"expiry_limited_by,omitempty"`
+	Renewal              *webhook.RenewalDetails  `json:"renewal,omitempty"`
+	Source               string                   `json:"source"`
+	ReferencedBy         []string                 `json:"referenced_by,omitempty"`
+	Endpoints            []webhook.EndpointStatus `json:"endpoints,omitempty"`
+	PolicyViolations     []string                 `json:"policy_violations,omitempty"`
+	RenewalTime          *time.Time               `json:"renewal_time,omitempty"`
+	RenewalAdvisories    []string                 `json:"renewal_advisories,omitempty"`
+	NotificationPolicies []string                 `json:"notification_policies,omitempty"`
+	LastNotified         *time.Time               `json:"last_notified,omitempty"`
+	Shard                string                   `json:"shard,omitempty"`
+	CheckedAt            time.Time                `json:"checked_at"`
+}
+
+// Certificates returns the certificates seen in the last check, ordered by namespace and name
+func (m *CertificateMonitor) Certificates() []CertificateStatus {
+	m.statusMutex.RLock()
+	defer m.statusMutex.RUnlock()
+
+	statuses := make([]CertificateStatus, len(m.statuses))
+	copy(statuses, m.statuses)
+	return statuses
+}
+
+// setStatuses replaces the monitor's view of certificates
+func (m *CertificateMonitor) setStatuses(statuses []CertificateStatus) {
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Namespace != statuses[j].Namespace {
+			return statuses[i].Namespace < statuses[j].Namespace
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+	m.statuses = statuses
+}
+
+// certificateStatus builds the view of a single certificate
+func (m *CertificateMonitor) certificateStatus(cert *certmanagerv1.Certificate, inspection *secretInspection, renewal *webhook.RenewalDetails, now time.Time) CertificateStatus {
+	status := CertificateStatus{
+		Cluster:   m.cluster,
+		Shard:     m.shardOwner(cert.Namespace),
+		Namespace: cert.Namespace,
+		Name:      cert.Name,
+		Issuer:    m.getIssuerName(cert),
+		DNSNames:  cert.Spec.DNSNames,
+		Ready:     m.isCertificateReady(cert),
+		State:     m.certificateState(cert, inspection, now),
+		Renewal:   renewal,
+		Source:    webhook.SourceCertificate,
+		CheckedAt: now,
+	}
+
+	if cert.Status.NotAfter != nil {
+		expiresAt := cert.Status.NotAfter.Time
+		status.ExpiresAt = &expiresAt
+	}
+
+	if window, ok := effectiveRenewal(cert); ok {
+		status.RenewalTime = &window.renewalTime
+	}
+	status.RenewalAdvisories = m.renewalAdvisories(cert, m.config.ExpirationThreshold)
+	status.NotificationPolicies = m.notificationPolicy(cert).Policies
+
+	_, status.Silenced = m.silencedBy(cert, now)
+
+	if inspection != nil {
+		status.Mismatches = inspection.mismatches
+		if inspection.limitedBy != nil {
+			status.LimitedBy = inspection.limitedBy.Describe()
+		}
+	}
+
+	m.notifiedMutex.RLock()
+	if lastNotified, exists := m.notifiedCerts[cert.Namespace+"/"+cert.Name]; exists {
+		status.LastNotified = &lastNotified
+	}
+	m.notifiedMutex.RUnlock()
+
+	return status
+}
+
+// certificateState classifies a certificate by its expiration. A certificate
+// within the expiration threshold is only expiring once it is due.
+func (m *CertificateMonitor) certificateState(cert *certmanagerv1.Certificate, inspection *secretInspection, now time.Time) string {
+	switch {
+	case cert.Status.NotAfter == nil:
+		return StateUnknown
+	case m.isCertificateExpired(cert, inspection, now):
+		return StateExpired
+	case m.isCertificateExpiring(cert, inspection, m.config.ExpirationThreshold, now) && isExpiryDue(cert, inspection, now):
+		return StateExpiring
+	default:
+		return StateOK
+	}
+}
@@ -0,0 +1,497 @@
+package notificationpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+// Group and Version of the policy resources
+const (
+	Group   = "cert-manager-notifier.io"
+	Version = "v1alpha1"
+)
+
+// Kinds of the policy resources
+const (
+	KindNotificationPolicy        = "NotificationPolicy"
+	KindClusterNotificationPolicy = "ClusterNotificationPolicy"
+)
+
+// defaultTimeout is the timeout of destinations that do not set one
+const defaultTimeout = 30 * time.Second
+
+var (
+	// NotificationPolicies are namespaced policies applying to the
+	// Certificates of their namespace
+	NotificationPolicies = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "notificationpolicies"}
+
+	// ClusterNotificationPolicies are cluster-scoped policies applying to the
+	// Certificates of any namespace
+	ClusterNotificationPolicies = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "clusternotificationpolicies"}
+)
+
+// Spec is the desired notification behaviour for the Certificates a policy selects
+type Spec struct {
+	// Selector selects Certificates by label, all Certificates when empty
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Namespaces limits a ClusterNotificationPolicy to these namespaces, all
+	// namespaces when empty. Ignored for NotificationPolicies.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// ExpirationThreshold overrides EXPIRATION_THRESHOLD for the
+	// destinations, e.g. "720h" or "14d"
+	ExpirationThreshold string `json:"expirationThreshold,omitempty"`
+
+	// RepeatInterval is how long a notification of the same type to the
+	// destinations is held back, e.g. "24h" or "7d"
+	RepeatInterval string `json:"repeatInterval,omitempty"`
+
+	// Destinations receive the notifications in addition to the global webhooks
+	Destinations []Destination `json:"destinations,omitempty"`
+
+	// Template is a Go template rendering the message sent to the
+	// destinations from the payload
+	Template string `json:"template,omitempty"`
+
+	// Schedule limits when the destinations are notified
+	Schedule *Schedule `json:"schedule,omitempty"`
+}
+
+// Schedule is a weekly window, such as working hours, in which the
+// destinations are notified. Notifications held back outside of it are sent
+// by the first check inside it.
+type Schedule struct {
+	// Days the window is open, e.g. ["Mon", "Fri"], every day when empty
+	Days []string `json:"days,omitempty"`
+
+	// Start and End of the window as "15:04", by default the whole day. A
+	// window ending before it starts runs past midnight.
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+
+	// TimeZone of the window, e.g. "Europe/Berlin", UTC when empty
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Destination is a webhook whose URL and secret headers are read from Secrets
+type Destination struct {
+	Name         string            `json:"name"`
+	URLSecretRef SecretKeySelector `json:"urlSecretRef"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	HeadersFrom  []HeaderSource    `json:"headersFrom,omitempty"`
+	Timeout      string            `json:"timeout,omitempty"`
+}
+
+// HeaderSource is a header whose value, such as a token, is read from a Secret
+type HeaderSource struct {
+	Name         string            `json:"name"`
+	SecretKeyRef SecretKeySelector `json:"secretKeyRef"`
+}
+
+// SecretKeySelector selects a key of a Secret. NotificationPolicies can only
+// reference Secrets in their own namespace, so Namespace is only used by
+// ClusterNotificationPolicies.
+type SecretKeySelector struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Key       string `json:"key"`
+}
+
+// resource is a NotificationPolicy or ClusterNotificationPolicy as stored in the cluster
+type resource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              Spec `json:"spec"`
+}
+
+// Policy is a validated policy with its Secrets resolved
+type Policy struct {
+	Kind      string
+	Name      string
+	Namespace string
+
+	selector            labels.Selector
+	namespaces          []string
+	expirationThreshold time.Duration
+	repeatInterval      time.Duration
+	webhooks            []config.WebhookConfig
+	template            *template.Template
+	schedule            *window
+}
+
+// String names the policy, e.g. "NotificationPolicy team-a/default"
+func (p *Policy) String() string {
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s", p.Kind, p.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", p.Kind, p.Namespace, p.Name)
+}
+
+// matches reports whether the policy applies to a Certificate
+func (p *Policy) matches(namespace string, certLabels map[string]string) bool {
+	if p.Kind == KindNotificationPolicy && p.Namespace != namespace {
+		return false
+	}
+	if len(p.namespaces) > 0 && !slices.Contains(p.namespaces, namespace) {
+		return false
+	}
+	return p.selector.Matches(labels.Set(certLabels))
+}
+
+// Resolved is the merged outcome of the policies applying to a Certificate.
+// Zero values leave the global configuration in effect for the Webhooks.
+type Resolved struct {
+	Policies            []string
+	ExpirationThreshold time.Duration
+	RepeatInterval      time.Duration
+	Webhooks            []config.WebhookConfig
+	Template            *template.Template
+
+	schedule *window
+}
+
+// Open reports whether the schedule of the policies lets the Webhooks be
+// notified at now. Policies without a schedule are always open.
+func (r Resolved) Open(now time.Time) bool {
+	return r.schedule.open(now)
+}
+
+// Set is the policies in effect
+type Set struct {
+	policies []*Policy
+}
+
+// NewSet creates a set of policies. NotificationPolicies take precedence over
+// ClusterNotificationPolicies, and policies of the same kind are ordered by name.
+func NewSet(policies []*Policy) *Set {
+	sorted := slices.Clone(policies)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Kind != sorted[j].Kind {
+			return sorted[i].Kind == KindNotificationPolicy
+		}
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return &Set{policies: sorted}
+}
+
+// Len returns the number of policies
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.policies)
+}
+
+// Match merges the policies applying to a Certificate. The first policy
+// setting a threshold, repeat interval, template or schedule wins, while the
+// destinations of every matching policy receive the notifications.
+func (s *Set) Match(namespace string, certLabels map[string]string) Resolved {
+	var resolved Resolved
+	if s == nil {
+		return resolved
+	}
+
+	for _, policy := range s.policies {
+		if !policy.matches(namespace, certLabels) {
+			continue
+		}
+
+		resolved.Policies = append(resolved.Policies, policy.String())
+		if resolved.ExpirationThreshold == 0 {
+			resolved.ExpirationThreshold = policy.expirationThreshold
+		}
+		if resolved.RepeatInterval == 0 {
+			resolved.RepeatInterval = policy.repeatInterval
+		}
+		if resolved.Template == nil {
+			resolved.Template = policy.template
+		}
+		if resolved.schedule == nil {
+			resolved.schedule = policy.schedule
+		}
+		resolved.Webhooks = append(resolved.Webhooks, policy.webhooks...)
+	}
+
+	return resolved
+}
+
+// Load lists the policies of a cluster, NotificationPolicies only in
+// namespace unless it is empty. Invalid policies are left out of the set and
+// reported in the error, which is only fatal when the set is nil.
+func Load(ctx context.Context, dynamicClient dynamic.Interface, kubeClient kubernetes.Interface, namespace string) (*Set, error) {
+	namespaced, err := dynamicClient.Resource(NotificationPolicies).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification policies: %w", err)
+	}
+
+	clusterScoped, err := dynamicClient.Resource(ClusterNotificationPolicies).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster notification policies: %w", err)
+	}
+
+	return compileAll(ctx, kubeClient, namespaced.Items, clusterScoped.Items)
+}
+
+// compileAll compiles the listed policies into a set, leaving out and
+// reporting the invalid ones
+func compileAll(ctx context.Context, kubeClient kubernetes.Interface, namespaced, clusterScoped []unstructured.Unstructured) (*Set, error) {
+	var policies []*Policy
+	var errs []error
+	for _, list := range []struct {
+		kind  string
+		items []unstructured.Unstructured
+	}{
+		{KindNotificationPolicy, namespaced},
+		{KindClusterNotificationPolicy, clusterScoped},
+	} {
+		for i := range list.items {
+			policy, err := compile(ctx, kubeClient, list.kind, &list.items[i])
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			policies = append(policies, policy)
+		}
+	}
+
+	return NewSet(policies), errors.Join(errs...)
+}
+
+// compile validates a policy resource and reads the Secrets its destinations reference
+func compile(ctx context.Context, kubeClient kubernetes.Interface, kind string, obj *unstructured.Unstructured) (*Policy, error) {
+	var res resource
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &res); err != nil {
+		return nil, fmt.Errorf("invalid %s %s: %w", kind, obj.GetName(), err)
+	}
+
+	policy := &Policy{Kind: kind, Name: res.Name, Namespace: res.Namespace}
+	if kind == KindClusterNotificationPolicy {
+		policy.Namespace = ""
+		policy.namespaces = res.Spec.Namespaces
+	}
+
+	invalid := func(err error) (*Policy, error) {
+		return nil, fmt.Errorf("invalid %s: %w", policy, err)
+	}
+
+	// A missing selector selects nothing to the apimachinery helpers, but
+	// every Certificate here
+	policy.selector = labels.Everything()
+	if res.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(res.Spec.Selector)
+		if err != nil {
+			return invalid(fmt.Errorf("selector: %w", err))
+		}
+		policy.selector = selector
+	}
+
+	var err error
+
+	if res.Spec.ExpirationThreshold != "" {
+		if policy.expirationThreshold, err = config.ParseDuration(res.Spec.ExpirationThreshold); err != nil {
+			return invalid(fmt.Errorf("expirationThreshold: %w", err))
+		}
+	}
+
+	if res.Spec.RepeatInterval != "" {
+		if policy.repeatInterval, err = config.ParseDuration(res.Spec.RepeatInterval); err != nil {
+			return invalid(fmt.Errorf("repeatInterval: %w", err))
+		}
+	}
+
+	if res.Spec.Template != "" {
+		if policy.template, err = template.New(policy.String()).Option("missingkey=error").Parse(res.Spec.Template); err != nil {
+			return invalid(fmt.Errorf("template: %w", err))
+		}
+	}
+
+	if res.Spec.Schedule != nil {
+		if policy.schedule, err = compileSchedule(res.Spec.Schedule); err != nil {
+			return invalid(fmt.Errorf("schedule: %w", err))
+		}
+	}
+
+	for _, destination := range res.Spec.Destinations {
+		webhook, err := resolveDestination(ctx, kubeClient, policy, destination)
+		if err != nil {
+			return invalid(fmt.Errorf("destination %s: %w", destination.Name, err))
+		}
+		policy.webhooks = append(policy.webhooks, webhook)
+	}
+
+	return policy, nil
+}
+
+// resolveDestination builds the webhook of a destination, reading its URL
+// and secret headers
+func resolveDestination(ctx context.Context, kubeClient kubernetes.Interface, policy *Policy, destination Destination) (config.WebhookConfig, error) {
+	if destination.Name == "" {
+		return config.WebhookConfig{}, fmt.Errorf("name is required")
+	}
+
+	url, err := readSecretKey(ctx, kubeClient, policy, destination.URLSecretRef)
+	if err != nil {
+		return config.WebhookConfig{}, fmt.Errorf("url: %w", err)
+	}
+
+	webhook := config.WebhookConfig{
+		// Name destinations after their policy so that history and metrics
+		// tell them apart from the global webhooks
+		Name:    policyPrefix(policy) + destination.Name,
+		URL:     url,
+		Headers: make(map[string]string, len(destination.Headers)+len(destination.HeadersFrom)),
+		Timeout: defaultTimeout,
+	}
+
+	for name, value := range destination.Headers {
+		webhook.Headers[name] = value
+	}
+
+	for _, header := range destination.HeadersFrom {
+		value, err := readSecretKey(ctx, kubeClient, policy, header.SecretKeyRef)
+		if err != nil {
+			return config.WebhookConfig{}, fmt.Errorf("header %s: %w", header.Name, err)
+		}
+		webhook.Headers[header.Name] = value
+	}
+
+	if destination.Timeout != "" {
+		if webhook.Timeout, err = config.ParseDuration(destination.Timeout); err != nil {
+			return config.WebhookConfig{}, fmt.Errorf("timeout: %w", err)
+		}
+	}
+
+	return webhook, nil
+}
+
+// readSecretKey reads a key of a Secret. NotificationPolicies always read
+// from their own namespace.
+func readSecretKey(ctx context.Context, kubeClient kubernetes.Interface, policy *Policy, ref SecretKeySelector) (string, error) {
+	namespace := ref.Namespace
+	if policy.Kind == KindNotificationPolicy {
+		namespace = policy.Namespace
+	}
+	if ref.Name == "" || ref.Key == "" || namespace == "" {
+		return "", fmt.Errorf("secret reference needs a name, key and, for cluster policies, a namespace")
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s/%s has no key %s", namespace, ref.Name, ref.Key)
+	}
+	return string(value), nil
+}
+
+// policyPrefix prefixes the destination names of a policy
+func policyPrefix(policy *Policy) string {
+	if policy.Namespace == "" {
+		return policy.Name + "/"
+	}
+	return policy.Namespace + "/" + policy.Name + "/"
+}
+
+// window is a compiled Schedule
+type window struct {
+	days     map[time.Weekday]bool
+	start    time.Duration
+	end      time.Duration
+	location *time.Location
+}
+
+// weekdays maps the day names of a Schedule to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// compileSchedule validates a Schedule
+func compileSchedule(schedule *Schedule) (*window, error) {
+	w := &window{end: 24 * time.Hour, location: time.UTC}
+
+	if len(schedule.Days) > 0 {
+		w.days = make(map[time.Weekday]bool, len(schedule.Days))
+		for _, day := range schedule.Days {
+			weekday, ok := weekdays[strings.ToLower(day)]
+			if !ok {
+				return nil, fmt.Errorf("unknown day %q, expected Mon to Sun", day)
+			}
+			w.days[weekday] = true
+		}
+	}
+
+	var err error
+	if schedule.Start != "" {
+		if w.start, err = timeOfDay(schedule.Start); err != nil {
+			return nil, fmt.Errorf("start: %w", err)
+		}
+	}
+	if schedule.End != "" {
+		if w.end, err = timeOfDay(schedule.End); err != nil {
+			return nil, fmt.Errorf("end: %w", err)
+		}
+	}
+
+	if schedule.TimeZone != "" {
+		if w.location, err = time.LoadLocation(schedule.TimeZone); err != nil {
+			return nil, fmt.Errorf("timeZone: %w", err)
+		}
+	}
+
+	return w, nil
+}
+
+// timeOfDay parses a time of day such as "09:30" into the time since midnight
+func timeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// open reports whether the window is open at now. A nil window always is.
+func (w *window) open(now time.Time) bool {
+	if w == nil {
+		return true
+	}
+
+	local := now.In(w.location)
+	if w.days != nil && !w.days[local.Weekday()] {
+		return false
+	}
+
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	switch {
+	case w.start == w.end:
+		return true
+	case w.start < w.end:
+		return offset >= w.start && offset < w.end
+	default:
+		return offset >= w.start || offset < w.end
+	}
+}
@@ -0,0 +1,245 @@
+package notificationpolicy
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// newPolicy creates a policy resource of kind with the given spec
+func newPolicy(kind, namespace, name string, spec map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	obj.SetAPIVersion(Group + "/" + Version)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+// newDynamicClient creates a fake dynamic client serving the policy resources
+func newDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		NotificationPolicies:        KindNotificationPolicy + "List",
+		ClusterNotificationPolicies: KindClusterNotificationPolicy + "List",
+	}, objects...)
+}
+
+// newSecret creates a Secret holding a single key
+func newSecret(namespace, name, key, value string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string][]byte{key: []byte(value)},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dynamicClient := newDynamicClient(
+		newPolicy(KindNotificationPolicy, "team-a", "default", map[string]any{
+			"expirationThreshold": "14d",
+			"template":            "{{ .Certificate.Name }} needs attention",
+			"destinations": []any{map[string]any{
+				"name":         "slack",
+				"urlSecretRef": map[string]any{"name": "alerting", "key": "url"},
+				"headersFrom": []any{map[string]any{
+					"name":         "Authorization",
+					"secretKeyRef": map[string]any{"name": "alerting", "key": "token"},
+				}},
+			}},
+		}),
+		newPolicy(KindNotificationPolicy, "team-a", "broken", map[string]any{
+			"repeatInterval": "soon",
+		}),
+		newPolicy(KindNotificationPolicy, "team-a", "stolen", map[string]any{
+			"destinations": []any{map[string]any{
+				"name":         "elsewhere",
+				"urlSecretRef": map[string]any{"name": "platform", "namespace": "platform", "key": "url"},
+			}},
+		}),
+		newPolicy(KindClusterNotificationPolicy, "", "production", map[string]any{
+			"selector":            map[string]any{"matchLabels": map[string]any{"env": "production"}},
+			"expirationThreshold": "60d",
+			"repeatInterval":      "12h",
+			"destinations": []any{map[string]any{
+				"name":         "pagerduty",
+				"urlSecretRef": map[string]any{"name": "platform", "namespace": "platform", "key": "url"},
+			}},
+		}),
+	)
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "alerting"},
+			Data: map[string][]byte{
+				"url":   []byte("https://hooks.example.com/team-a"),
+				"token": []byte("Bearer secret"),
+			},
+		},
+		newSecret("platform", "platform", "url", "https://hooks.example.com/platform"),
+	)
+
+	set, err := Load(context.Background(), dynamicClient, kubeClient, "")
+	if set == nil {
+		t.Fatalf("Expected a policy set, got error: %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "team-a/broken") || !strings.Contains(err.Error(), "team-a/stolen") {
+		t.Errorf("Expected the broken and stolen policies to be reported, got: %v", err)
+	}
+	if set.Len() != 2 {
+		t.Fatalf("Expected 2 valid policies, got %d", set.Len())
+	}
+
+	resolved := set.Match("team-a", map[string]string{"env": "production"})
+	if len(resolved.Policies) != 2 || resolved.Policies[0] != "NotificationPolicy team-a/default" {
+		t.Errorf("Expected the namespaced policy before the cluster policy, got %v", resolved.Policies)
+	}
+	if resolved.ExpirationThreshold != 14*24*time.Hour {
+		t.Errorf("Expected the namespaced threshold to win, got %s", resolved.ExpirationThreshold)
+	}
+	if resolved.RepeatInterval != 12*time.Hour {
+		t.Errorf("Expected the cluster repeat interval, got %s", resolved.RepeatInterval)
+	}
+	if resolved.Template == nil {
+		t.Error("Expected the namespaced template")
+	}
+	if len(resolved.Webhooks) != 2 {
+		t.Fatalf("Expected the destinations of both policies, got %+v", resolved.Webhooks)
+	}
+	if resolved.Webhooks[0].Name != "team-a/default/slack" || resolved.Webhooks[0].URL != "https://hooks.example.com/team-a" {
+		t.Errorf("Unexpected namespaced destination: %+v", resolved.Webhooks[0])
+	}
+	if resolved.Webhooks[0].Headers["Authorization"] != "Bearer secret" {
+		t.Errorf("Expected the header read from the secret, got %v", resolved.Webhooks[0].Headers)
+	}
+	if resolved.Webhooks[1].Name != "production/pagerduty" {
+		t.Errorf("Unexpected cluster destination: %+v", resolved.Webhooks[1])
+	}
+
+	// Namespaced policies stay in their namespace and selectors apply
+	if resolved := set.Match("team-b", map[string]string{"env": "staging"}); len(resolved.Policies) != 0 {
+		t.Errorf("Expected no policies for team-b staging, got %v", resolved.Policies)
+	}
+	if resolved := set.Match("team-b", map[string]string{"env": "production"}); len(resolved.Policies) != 1 || resolved.ExpirationThreshold != 60*24*time.Hour {
+		t.Errorf("Expected only the cluster policy for team-b production, got %+v", resolved)
+	}
+}
+
+func TestSet_MatchNil(t *testing.T) {
+	var set *Set
+	if resolved := set.Match("default", nil); len(resolved.Policies) != 0 || resolved.ExpirationThreshold != 0 {
+		t.Errorf("Expected nothing from a nil set, got %+v", resolved)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	// 2024-01-01 is a Monday
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, time.January, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		now      time.Time
+		open     bool
+	}{
+		{"whole day", Schedule{Days: []string{"Mon"}}, at(3, 0), true},
+		{"other day", Schedule{Days: []string{"tue", "wed"}}, at(12, 0), false},
+		{"inside hours", Schedule{Start: "09:00", End: "17:00"}, at(9, 0), true},
+		{"after hours", Schedule{Start: "09:00", End: "17:00"}, at(17, 0), false},
+		{"overnight", Schedule{Start: "22:00", End: "06:00"}, at(5, 59), true},
+		{"outside overnight", Schedule{Start: "22:00", End: "06:00"}, at(12, 0), false},
+		{"time zone", Schedule{Start: "09:00", End: "17:00", TimeZone: "Asia/Tokyo"}, at(1, 0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := compileSchedule(&tt.schedule)
+			if err != nil {
+				t.Fatalf("Expected a valid schedule, got: %v", err)
+			}
+			if open := w.open(tt.now); open != tt.open {
+				t.Errorf("Expected open=%v at %s, got %v", tt.open, tt.now, open)
+			}
+		})
+	}
+
+	for _, invalid := range []Schedule{{Days: []string{"Someday"}}, {Start: "9am"}, {TimeZone: "Nowhere/City"}} {
+		if _, err := compileSchedule(&invalid); err == nil {
+			t.Errorf("Expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dynamicClient := newDynamicClient(newPolicy(KindNotificationPolicy, "team-a", "default", map[string]any{"repeatInterval": "12h"}))
+	kubeClient := kubefake.NewSimpleClientset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watch := NewWatch(ctx, dynamicClient, "team-a")
+	defer watch.Stop()
+
+	// Loading before the caches synced lists the policies from the API
+	set, err := watch.Load(ctx, kubeClient)
+	if err != nil || set.Len() != 1 {
+		t.Fatalf("Expected 1 policy, got %d: %v", set.Len(), err)
+	}
+
+	// Wait for the informers to watch, as the fake client drops earlier events
+	deadline := time.Now().Add(5 * time.Second)
+	for !watch.synced() || countWatches(dynamicClient) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the policy watch")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	added := newPolicy(KindClusterNotificationPolicy, "", "production", map[string]any{"expirationThreshold": "60d"})
+	if _, err := dynamicClient.Resource(ClusterNotificationPolicies).Create(ctx, added, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+
+	for {
+		set, err := watch.Load(ctx, kubeClient)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if set.Len() == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the created policy to be watched, got %d policies", set.Len())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Once synced, the policies are no longer listed from the API
+	lists := 0
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "list" {
+			lists++
+		}
+	}
+	if lists != 4 {
+		t.Errorf("Expected the initial load and the informers to list the policies 4 times, got %d", lists)
+	}
+}
+
+// countWatches counts the watches started on the fake client
+func countWatches(dynamicClient *dynamicfake.FakeDynamicClient) int {
+	watches := 0
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "watch" {
+			watches++
+		}
+	}
+	return watches
+}
@@ -0,0 +1,90 @@
+package notificationpolicy
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Watch keeps the policies of a cluster in informer caches, so that loading
+// them does not list them from the API on every check
+type Watch struct {
+	// Namespace is where NotificationPolicies are watched, all namespaces when empty
+	Namespace string
+
+	dynamicClient dynamic.Interface
+	namespaced    cache.GenericLister
+	clusterScoped cache.GenericLister
+	synced        func() bool
+	stop          context.CancelFunc
+}
+
+// NewWatch starts watching the policies of a cluster, NotificationPolicies
+// only in namespace unless it is empty, until Stop is called or the context
+// is cancelled
+func NewWatch(ctx context.Context, dynamicClient dynamic.Interface, namespace string) *Watch {
+	// ClusterNotificationPolicies cannot be listed in a namespace
+	namespacedFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, namespace, nil)
+	clusterFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
+	namespaced := namespacedFactory.ForResource(NotificationPolicies)
+	clusterScoped := clusterFactory.ForResource(ClusterNotificationPolicies)
+
+	watchCtx, stop := context.WithCancel(ctx)
+	namespacedFactory.Start(watchCtx.Done())
+	clusterFactory.Start(watchCtx.Done())
+
+	return &Watch{
+		Namespace:     namespace,
+		dynamicClient: dynamicClient,
+		namespaced:    namespaced.Lister(),
+		clusterScoped: clusterScoped.Lister(),
+		synced: func() bool {
+			return namespaced.Informer().HasSynced() && clusterScoped.Informer().HasSynced()
+		},
+		stop: stop,
+	}
+}
+
+// Stop stops watching the policies
+func (w *Watch) Stop() {
+	w.stop()
+}
+
+// Load compiles the watched policies like the package level Load, listing
+// them from the API until the caches have synced. The Secrets of their
+// destinations are read on every call, so rotated Secrets take effect.
+func (w *Watch) Load(ctx context.Context, kubeClient kubernetes.Interface) (*Set, error) {
+	if !w.synced() {
+		return Load(ctx, w.dynamicClient, kubeClient, w.Namespace)
+	}
+
+	namespaced, err := w.namespaced.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification policies: %w", err)
+	}
+
+	clusterScoped, err := w.clusterScoped.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster notification policies: %w", err)
+	}
+
+	return compileAll(ctx, kubeClient, unstructuredItems(namespaced), unstructuredItems(clusterScoped))
+}
+
+// unstructuredItems copies the cached objects into a list
+func unstructuredItems(objects []runtime.Object) []unstructured.Unstructured {
+	items := make([]unstructured.Unstructured, 0, len(objects))
+	for _, obj := range objects {
+		if item, ok := obj.(*unstructured.Unstructured); ok {
+			items = append(items, *item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,171 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+// Key algorithms as reported by cert-manager and Go's x509 package
+const (
+	AlgorithmRSA     = "RSA"
+	AlgorithmECDSA   = "ECDSA"
+	AlgorithmEd25519 = "Ed25519"
+)
+
+// defaultNamespaces is the AllowedIssuers key for namespaces without their own entry
+const defaultNamespaces = "*"
+
+// Duration is a time.Duration that unmarshals from strings such as "720h" or "90d"
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var val string
+	if err := json.Unmarshal(data, &val); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+
+	duration, err := config.ParseDuration(val)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// Policy is the set of rules certificates must comply with. Zero values disable a rule.
+type Policy struct {
+	// MinKeySize is the minimum key size in bits per key algorithm, e.g. {"RSA": 3072}
+	MinKeySize map[string]int `json:"minKeySize,omitempty"`
+
+	// AllowedKeyAlgorithms lists the permitted key algorithms
+	AllowedKeyAlgorithms []string `json:"allowedKeyAlgorithms,omitempty"`
+
+	// MaxDuration is the longest permitted certificate lifetime
+	MaxDuration Duration `json:"maxDuration,omitempty"`
+
+	// RequireRenewBefore requires Certificates to set renewBefore or renewBeforePercentage
+	RequireRenewBefore bool `json:"requireRenewBefore,omitempty"`
+
+	// ForbidWildcardNamespaces lists namespaces where wildcard DNS names are not allowed
+	ForbidWildcardNamespaces []string `json:"forbidWildcardNamespaces,omitempty"`
+
+	// AllowedIssuers lists the issuers permitted per namespace as Kind/name,
+	// e.g. ClusterIssuer/letsencrypt-prod. Kinds of external issuers are
+	// qualified by their API group, e.g. AWSPCAClusterIssuer.awspca.cert-manager.io.
+	// The "*" entry applies to namespaces without their own entry.
+	AllowedIssuers map[string][]string `json:"allowedIssuers,omitempty"`
+}
+
+// Subject is the certificate being evaluated. IssuerKind is qualified by
+// the API group for external issuers.
+type Subject struct {
+	Namespace          string
+	IssuerKind         string
+	Issuer             string
+	KeyAlgorithm       string
+	KeySize            int
+	Duration           time.Duration
+	RenewBeforeDefined bool
+	DNSNames           []string
+}
+
+// Load reads a policy from a YAML or JSON file
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var policy Policy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", path, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// validate checks that every allowed issuer names its kind
+func (p *Policy) validate() error {
+	for namespace, issuers := range p.AllowedIssuers {
+		for _, issuer := range issuers {
+			kind, name, ok := strings.Cut(issuer, "/")
+			if !ok || kind == "" || name == "" || strings.Contains(name, "/") {
+				return fmt.Errorf("allowed issuer %q for namespace %s must be Kind/name, e.g. ClusterIssuer/%s", issuer, namespace, issuer)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate returns a description of each rule the subject violates
+func (p *Policy) Evaluate(subject Subject) []string {
+	var violations []string
+
+	if len(p.AllowedKeyAlgorithms) > 0 && subject.KeyAlgorithm != "" && !containsFold(p.AllowedKeyAlgorithms, subject.KeyAlgorithm) {
+		violations = append(violations, fmt.Sprintf("key algorithm %s is not allowed (allowed: %s)", subject.KeyAlgorithm, strings.Join(p.AllowedKeyAlgorithms, ", ")))
+	}
+
+	for algorithm, minSize := range p.MinKeySize {
+		if strings.EqualFold(algorithm, subject.KeyAlgorithm) && subject.KeySize > 0 && subject.KeySize < minSize {
+			violations = append(violations, fmt.Sprintf("%s key size %d is below the minimum of %d", subject.KeyAlgorithm, subject.KeySize, minSize))
+		}
+	}
+
+	if p.MaxDuration > 0 && subject.Duration > time.Duration(p.MaxDuration) {
+		violations = append(violations, fmt.Sprintf("duration %s exceeds the maximum of %s", subject.Duration, time.Duration(p.MaxDuration)))
+	}
+
+	if p.RequireRenewBefore && !subject.RenewBeforeDefined {
+		violations = append(violations, "renewBefore or renewBeforePercentage is not set")
+	}
+
+	if containsFold(p.ForbidWildcardNamespaces, subject.Namespace) {
+		var wildcards []string
+		for _, dnsName := range subject.DNSNames {
+			if strings.HasPrefix(dnsName, "*.") {
+				wildcards = append(wildcards, dnsName)
+			}
+		}
+		if len(wildcards) > 0 {
+			violations = append(violations, fmt.Sprintf("wildcard DNS names are not allowed in namespace %s: %s", subject.Namespace, strings.Join(wildcards, ", ")))
+		}
+	}
+
+	issuer := subject.IssuerKind + "/" + subject.Issuer
+	if allowed, ok := p.allowedIssuers(subject.Namespace); ok && !containsFold(allowed, issuer) {
+		violations = append(violations, fmt.Sprintf("issuer %s is not allowed in namespace %s (allowed: %s)", issuer, subject.Namespace, strings.Join(allowed, ", ")))
+	}
+
+	sort.Strings(violations)
+	return violations
+}
+
+// allowedIssuers returns the issuers permitted in a namespace, if restricted
+func (p *Policy) allowedIssuers(namespace string) ([]string, bool) {
+	if allowed, ok := p.AllowedIssuers[namespace]; ok {
+		return allowed, true
+	}
+	allowed, ok := p.AllowedIssuers[defaultNamespaces]
+	return allowed, ok
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
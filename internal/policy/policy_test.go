@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	data := `
+minKeySize:
+  RSA: 3072
+allowedKeyAlgorithms: [RSA, ECDSA]
+maxDuration: 90d
+requireRenewBefore: true
+forbidWildcardNamespaces: [payments]
+allowedIssuers:
+  payments: [Issuer/internal-ca]
+  "*": [ClusterIssuer/letsencrypt-prod, Issuer/internal-ca]
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+
+	policy, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if time.Duration(policy.MaxDuration) != 90*24*time.Hour {
+		t.Errorf("Expected max duration of 90 days, got %v", time.Duration(policy.MaxDuration))
+	}
+
+	if policy.MinKeySize[AlgorithmRSA] != 3072 || len(policy.AllowedIssuers) != 2 {
+		t.Errorf("Unexpected policy: %+v", policy)
+	}
+
+	if err := os.WriteFile(path, []byte("maxDuraton: 90d\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+
+	if err := os.WriteFile(path, []byte("allowedIssuers:\n  \"*\": [letsencrypt-prod]\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "must be Kind/name") {
+		t.Errorf("Expected an error for an allowed issuer without a kind, got: %v", err)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	policy := &Policy{
+		MinKeySize:               map[string]int{AlgorithmRSA: 3072},
+		AllowedKeyAlgorithms:     []string{AlgorithmRSA, AlgorithmECDSA},
+		MaxDuration:              Duration(90 * 24 * time.Hour),
+		RequireRenewBefore:       true,
+		ForbidWildcardNamespaces: []string{"payments"},
+		AllowedIssuers: map[string][]string{
+			"payments": {"Issuer/internal-ca"},
+			"*":        {"ClusterIssuer/letsencrypt-prod", "Issuer/internal-ca"},
+		},
+	}
+
+	compliant := Subject{
+		Namespace:          "default",
+		IssuerKind:         "ClusterIssuer",
+		Issuer:             "letsencrypt-prod",
+		KeyAlgorithm:       AlgorithmECDSA,
+		KeySize:            256,
+		Duration:           90 * 24 * time.Hour,
+		RenewBeforeDefined: true,
+		DNSNames:           []string{"*.example.com"},
+	}
+	if violations := policy.Evaluate(compliant); len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+
+	violations := policy.Evaluate(Subject{
+		Namespace:    "payments",
+		IssuerKind:   "ClusterIssuer",
+		Issuer:       "letsencrypt-prod",
+		KeyAlgorithm: AlgorithmRSA,
+		KeySize:      2048,
+		Duration:     365 * 24 * time.Hour,
+		DNSNames:     []string{"*.pay.example.com", "pay.example.com"},
+	})
+
+	expected := []string{
+		"RSA key size 2048 is below the minimum of 3072",
+		"duration 8760h0m0s exceeds the maximum of 2160h0m0s",
+		"issuer ClusterIssuer/letsencrypt-prod is not allowed in namespace payments (allowed: Issuer/internal-ca)",
+		"renewBefore or renewBeforePercentage is not set",
+		"wildcard DNS names are not allowed in namespace payments: *.pay.example.com",
+	}
+	if len(violations) != len(expected) {
+		t.Fatalf("Expected %d violations, got %v", len(expected), violations)
+	}
+	for i := range expected {
+		if violations[i] != expected[i] {
+			t.Errorf("Expected violation %q, got %q", expected[i], violations[i])
+		}
+	}
+
+	violations = policy.Evaluate(Subject{Namespace: "default", IssuerKind: "Issuer", Issuer: "internal-ca", KeyAlgorithm: AlgorithmEd25519, RenewBeforeDefined: true})
+	if len(violations) != 1 || violations[0] != "key algorithm Ed25519 is not allowed (allowed: RSA, ECDSA)" {
+		t.Errorf("Expected a key algorithm violation, got %v", violations)
+	}
+
+	// A namespaced Issuer sharing the name of an allowed ClusterIssuer is not allowed
+	violations = policy.Evaluate(Subject{Namespace: "default", IssuerKind: "Issuer", Issuer: "letsencrypt-prod", KeyAlgorithm: AlgorithmRSA, KeySize: 3072, RenewBeforeDefined: true})
+	if len(violations) != 1 || violations[0] != "issuer Issuer/letsencrypt-prod is not allowed in namespace default (allowed: ClusterIssuer/letsencrypt-prod, Issuer/internal-ca)" {
+		t.Errorf("Expected an issuer kind violation, got %v", violations)
+	}
+}
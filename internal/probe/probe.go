@@ -0,0 +1,190 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPort is the port probed for DNS names without a configured endpoint
+const DefaultPort = "443"
+
+// Endpoint is an address to probe and the server name to send with SNI
+type Endpoint struct {
+	Address    string
+	ServerName string
+}
+
+// Endpoints returns the endpoints to probe for a certificate: the configured
+// addresses if any, otherwise each non-wildcard DNS name on the default port.
+// Configured addresses must name one of the certificate's DNS names or IP
+// addresses or be allowed by allowlist, and are returned as rejected otherwise.
+func Endpoints(configured string, dnsNames, ipAddresses []string, allowlist Allowlist) (endpoints []Endpoint, rejected []string) {
+	serverName := ""
+	if len(dnsNames) > 0 {
+		serverName = strings.TrimPrefix(dnsNames[0], "*.")
+	}
+
+	certificateHosts := Allowlist{hosts: append(append([]string(nil), dnsNames...), ipAddresses...)}
+
+	configuredAny := false
+	for _, address := range strings.Split(configured, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		configuredAny = true
+
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(address, DefaultPort)
+		}
+		host, _, _ := net.SplitHostPort(address)
+		if !certificateHosts.Allows(host) && !allowlist.Allows(host) {
+			rejected = append(rejected, address)
+			continue
+		}
+		endpoints = append(endpoints, Endpoint{Address: address, ServerName: serverName})
+	}
+	if configuredAny {
+		return endpoints, rejected
+	}
+
+	for _, dnsName := range dnsNames {
+		if strings.HasPrefix(dnsName, "*.") {
+			continue
+		}
+		endpoints = append(endpoints, Endpoint{Address: net.JoinHostPort(dnsName, DefaultPort), ServerName: dnsName})
+	}
+	return endpoints, nil
+}
+
+// Allowlist holds the hosts that may be probed: host names, *.domain
+// wildcards matching a single label, IP addresses and CIDR ranges
+type Allowlist struct {
+	hosts    []string
+	networks []*net.IPNet
+}
+
+// NewAllowlist parses allowlist entries
+func NewAllowlist(entries []string) (Allowlist, error) {
+	var allowlist Allowlist
+	for _, entry := range entries {
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return Allowlist{}, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+			}
+			allowlist.networks = append(allowlist.networks, network)
+			continue
+		}
+		allowlist.hosts = append(allowlist.hosts, entry)
+	}
+	return allowlist, nil
+}
+
+// Allows reports whether host is on the allowlist
+func (a Allowlist) Allows(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		for _, network := range a.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		for _, allowed := range a.hosts {
+			if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range a.hosts {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			label, rest, found := strings.Cut(host, ".")
+			if found && label != "" && rest == suffix {
+				return true
+			}
+			continue
+		}
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is the outcome of probing an endpoint
+type Result struct {
+	Endpoint    Endpoint
+	Certificate *x509.Certificate
+	Err         error
+}
+
+// Probe probes endpoints concurrently, at most concurrency at a time, and
+// returns the results in the order of the endpoints. Each probe is bounded
+// by timeout and all of them by ctx; endpoints not probed before ctx is done
+// report its error.
+func Probe(ctx context.Context, endpoints []Endpoint, timeout time.Duration, concurrency int) []Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]Result, len(endpoints))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, endpoint := range endpoints {
+		results[i].Endpoint = endpoint
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = fmt.Errorf("%s was not probed: %w", endpoint.Address, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i].Certificate, results[i].Err = ServedCertificate(ctx, endpoint, timeout)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// ServedCertificate performs a TLS handshake with the endpoint and returns
+// the leaf certificate it serves. The served chain is not verified, since
+// the point is to see what clients are given even if it is invalid.
+func ServedCertificate(ctx context.Context, endpoint Endpoint, timeout time.Duration) (*x509.Certificate, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName:         endpoint.ServerName,
+			InsecureSkipVerify: true, // the served certificate is inspected, not trusted
+		},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", endpoint.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", endpoint.Address, err)
+	}
+	defer conn.Close()
+
+	certificates := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("%s served no certificate", endpoint.Address)
+	}
+	return certificates[0], nil
+}
@@ -0,0 +1,147 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEndpoints(t *testing.T) {
+	endpoints, _ := Endpoints("", []string{"*.example.com", "example.com", "www.example.com"}, nil, Allowlist{})
+	if len(endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %+v", endpoints)
+	}
+
+	if endpoints[0].Address != "example.com:443" || endpoints[0].ServerName != "example.com" {
+		t.Errorf("Unexpected endpoint: %+v", endpoints[0])
+	}
+
+	allowlist, err := NewAllowlist([]string{"10.0.0.0/24", "*.internal"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	endpoints, rejected := Endpoints("10.0.0.1:8443, lb.internal, www.example.com:8443", []string{"example.com", "www.example.com"}, nil, allowlist)
+	if len(endpoints) != 3 || len(rejected) != 0 {
+		t.Fatalf("Expected 3 configured endpoints, got %+v, rejected %v", endpoints, rejected)
+	}
+
+	if endpoints[0].Address != "10.0.0.1:8443" || endpoints[1].Address != "lb.internal:443" {
+		t.Errorf("Unexpected configured addresses: %+v", endpoints)
+	}
+
+	if endpoints[1].ServerName != "example.com" {
+		t.Errorf("Expected configured endpoints to use the first DNS name for SNI, got %q", endpoints[1].ServerName)
+	}
+
+	// Hosts that are neither names of the certificate nor allowed are not probed
+	endpoints, rejected = Endpoints("169.254.169.254:80, metadata.google.internal, 10.0.0.5", []string{"example.com"}, []string{"10.0.0.5"}, Allowlist{})
+	if len(endpoints) != 1 || endpoints[0].Address != "10.0.0.5:443" {
+		t.Errorf("Expected only the certificate's IP address to be probed, got %+v", endpoints)
+	}
+	if len(rejected) != 2 || rejected[0] != "169.254.169.254:80" || rejected[1] != "metadata.google.internal:443" {
+		t.Errorf("Expected the other endpoints to be rejected, got %v", rejected)
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	allowlist, err := NewAllowlist([]string{"lb.example.com", "*.svc.cluster.local", "192.168.0.0/16", "10.1.2.3"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	tests := map[string]bool{
+		"lb.example.com":                  true,
+		"LB.example.com.":                 true,
+		"ingress.svc.cluster.local":       true,
+		"svc.cluster.local":               false,
+		"ingress.nginx.svc.cluster.local": false,
+		"192.168.10.1":                    true,
+		"10.1.2.3":                        true,
+		"10.1.2.4":                        false,
+		"other.example.com":               false,
+	}
+	for host, expected := range tests {
+		if allowed := allowlist.Allows(host); allowed != expected {
+			t.Errorf("%s: expected allowed %v, got %v", host, expected, allowed)
+		}
+	}
+
+	if _, err := NewAllowlist([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("Expected an error for an invalid CIDR range")
+	}
+}
+
+func TestProbe(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// An endpoint that accepts connections but never completes the handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	address := strings.TrimPrefix(server.URL, "https://")
+	endpoints := []Endpoint{
+		{Address: listener.Addr().String()},
+		{Address: listener.Addr().String()},
+		{Address: address},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	results := Probe(ctx, endpoints, 5*time.Second, 2)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected probing to stop at the deadline, took %s", elapsed)
+	}
+
+	if len(results) != 3 || results[0].Err == nil || results[1].Err == nil {
+		t.Fatalf("Expected the hanging endpoints to fail, got %+v", results)
+	}
+
+	// The third endpoint waited for a free slot until the deadline passed
+	if results[2].Endpoint.Address != address || results[2].Err == nil {
+		t.Errorf("Expected the waiting endpoint to fail at the deadline, got %+v", results[2])
+	}
+
+	results = Probe(context.Background(), endpoints[2:], 5*time.Second, 2)
+	if results[0].Err != nil || results[0].Certificate.SerialNumber.Cmp(server.Certificate().SerialNumber) != 0 {
+		t.Errorf("Expected the served certificate, got %+v", results[0])
+	}
+}
+
+func TestServedCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	address := strings.TrimPrefix(server.URL, "https://")
+	leaf, err := ServedCertificate(context.Background(), Endpoint{Address: address, ServerName: "example.com"}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if leaf.SerialNumber.Cmp(server.Certificate().SerialNumber) != 0 {
+		t.Errorf("Expected the served certificate, got serial %v", leaf.SerialNumber)
+	}
+
+	if _, err := ServedCertificate(context.Background(), Endpoint{Address: "127.0.0.1:1"}, time.Second); err == nil {
+		t.Error("Expected an error for an unreachable endpoint")
+	}
+}
@@ -0,0 +1,174 @@
+package reload
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+// Reloader reloads the configuration when the files in its config
+// directories, its secret files or the Secrets it references change or a
+// reload is requested, and applies it when valid
+type Reloader struct {
+	load     func() (*config.Config, error)
+	apply    func(*config.Config) error
+	dirs     []string
+	interval time.Duration
+	logger   *logrus.Entry
+
+	mutex       sync.Mutex
+	current     *config.Config
+	fingerprint string
+}
+
+// NewReloader creates a reloader for the running configuration. load reads
+// a new configuration and apply puts it into effect, or fails to leave the
+// running configuration in place.
+func NewReloader(current *config.Config, load func() (*config.Config, error), apply func(*config.Config) error, logger *logrus.Entry) *Reloader {
+	reloader := &Reloader{
+		load:     load,
+		apply:    apply,
+		dirs:     current.ConfigDirs,
+		interval: current.ReloadInterval,
+		logger:   logger.WithField("component", "config-reloader"),
+		current:  current,
+	}
+
+	reloader.fingerprint = reloader.fingerprintFiles()
+
+	return reloader
+}
+
+// Run reloads the configuration whenever the watched files or Secrets change
+// or a signal arrives, until the context is cancelled
+func (r *Reloader) Run(ctx context.Context, signals <-chan os.Signal) {
+	var poll <-chan time.Time
+	if len(r.dirs) > 0 || len(r.current.SecretFiles) > 0 || len(r.current.SecretRefs) > 0 {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			r.logger.WithField("signal", sig.String()).Info("Reloading configuration")
+			r.Reload()
+		case <-poll:
+			if r.changed() {
+				r.logger.Info("Configuration files changed, reloading configuration")
+				r.Reload()
+			} else {
+				r.refresh()
+			}
+		}
+	}
+}
+
+// fingerprintFiles hashes the config directories and the secret files of the
+// running configuration
+func (r *Reloader) fingerprintFiles() string {
+	r.mutex.Lock()
+	files := r.current.SecretFiles
+	r.mutex.Unlock()
+
+	fingerprint, err := config.Fingerprint(r.dirs, files)
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to read configuration files")
+	}
+	return fingerprint
+}
+
+// changed reports whether the watched files changed since the last check
+func (r *Reloader) changed() bool {
+	fingerprint := r.fingerprintFiles()
+	if fingerprint == "" {
+		return false
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if fingerprint == r.fingerprint {
+		return false
+	}
+	r.fingerprint = fingerprint
+	return true
+}
+
+// Reload loads, validates and applies the configuration. On any failure the
+// running configuration stays in effect.
+func (r *Reloader) Reload() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	changes, err := r.reload(false)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to reload configuration, keeping the running configuration")
+		return err
+	}
+
+	if len(changes) == 0 {
+		r.logger.Info("Configuration unchanged")
+		return nil
+	}
+	r.logger.WithField("changes", changes).Info("Configuration reloaded")
+	return nil
+}
+
+// refresh re-reads the Secrets referenced by the running configuration,
+// since they cannot be fingerprinted, and applies the configuration only
+// when it changed
+func (r *Reloader) refresh() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.current.SecretRefs) == 0 {
+		return
+	}
+
+	changes, err := r.reload(true)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to refresh referenced Secrets, keeping the running configuration")
+		return
+	}
+	if len(changes) > 0 {
+		r.logger.WithField("changes", changes).Info("Referenced Secrets changed, configuration reloaded")
+	}
+}
+
+// reload replaces the running configuration and returns the changes,
+// holding the mutex. Quiet reloads do not warn about startup settings.
+func (r *Reloader) reload(quiet bool) ([]string, error) {
+	cfg, err := r.load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if kept := cfg.KeepStartupSettings(r.current); len(kept) > 0 && !quiet {
+		r.logger.WithField("settings", kept).Warn("Some changed settings only take effect after a restart")
+	}
+
+	changes := config.Diff(r.current, cfg)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	if err := r.apply(cfg); err != nil {
+		return nil, fmt.Errorf("failed to apply configuration: %w", err)
+	}
+
+	r.current = cfg
+	return changes, nil
+}
@@ -0,0 +1,176 @@
+package reload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+// newConfig creates a valid configuration with the given check interval
+func newConfig(checkInterval time.Duration) *config.Config {
+	return &config.Config{
+		CheckInterval:       checkInterval,
+		ExpirationThreshold: 30 * 24 * time.Hour,
+		Webhooks: []config.WebhookConfig{{
+			Name:    "webhook-1",
+			URL:     "https://example.com/webhook",
+			Timeout: 30 * time.Second,
+		}},
+		ProbeTimeout:     5 * time.Second,
+		ProbeConcurrency: 5,
+		ReloadInterval:   10 * time.Millisecond,
+	}
+}
+
+func TestReload(t *testing.T) {
+	running := newConfig(time.Hour)
+	next := newConfig(2 * time.Hour)
+
+	var applied *config.Config
+	reloader := NewReloader(running,
+		func() (*config.Config, error) { return next, nil },
+		func(cfg *config.Config) error { applied = cfg; return nil },
+		logrus.NewEntry(logrus.New()))
+
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if applied != next || reloader.current != next {
+		t.Error("Expected the new configuration to be applied")
+	}
+
+	// Reloading the same configuration applies nothing
+	applied = nil
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if applied != nil {
+		t.Error("Expected an unchanged configuration not to be applied")
+	}
+}
+
+func TestReload_KeepsRunningConfiguration(t *testing.T) {
+	running := newConfig(time.Hour)
+
+	invalid := newConfig(2 * time.Hour)
+	invalid.Webhooks[0].URL = "ftp://example.com/webhook"
+
+	tests := []struct {
+		name  string
+		load  func() (*config.Config, error)
+		apply func(*config.Config) error
+		err   string
+	}{
+		{
+			name:  "load fails",
+			load:  func() (*config.Config, error) { return nil, errors.New("boom") },
+			apply: func(*config.Config) error { return nil },
+			err:   "failed to load configuration",
+		},
+		{
+			name:  "invalid configuration",
+			load:  func() (*config.Config, error) { return invalid, nil },
+			apply: func(*config.Config) error { return nil },
+			err:   "invalid configuration",
+		},
+		{
+			name:  "apply fails",
+			load:  func() (*config.Config, error) { return newConfig(2 * time.Hour), nil },
+			apply: func(*config.Config) error { return errors.New("boom") },
+			err:   "failed to apply configuration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reloader := NewReloader(running, tt.load, tt.apply, logrus.NewEntry(logrus.New()))
+
+			err := reloader.Reload()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got: %v", tt.err, err)
+			}
+			if reloader.current != running {
+				t.Error("Expected the running configuration to be kept")
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CHECK_INTERVAL")
+	if err := os.WriteFile(path, []byte("1h"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	running := newConfig(time.Hour)
+	running.ConfigDirs = []string{dir}
+
+	applied := make(chan *config.Config, 2)
+	reloader := NewReloader(running,
+		func() (*config.Config, error) { return newConfig(2 * time.Hour), nil },
+		func(cfg *config.Config) error { applied <- cfg; return nil },
+		logrus.NewEntry(logrus.New()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	go reloader.Run(ctx, signals)
+
+	if err := os.WriteFile(path, []byte("2h"), 0o600); err != nil {
+		t.Fatalf("Failed to update file: %v", err)
+	}
+
+	select {
+	case cfg := <-applied:
+		if cfg.CheckInterval != 2*time.Hour {
+			t.Errorf("Expected the reloaded check interval, got %s", cfg.CheckInterval)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a file change to reload the configuration")
+	}
+
+	// A signal reloads too, but the configuration is now unchanged
+	signals <- syscall.SIGHUP
+	select {
+	case <-applied:
+		t.Error("Expected an unchanged configuration not to be applied")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	running := newConfig(time.Hour)
+	running.SecretRefs = []string{"monitoring/notifier/urls"}
+
+	rotated := newConfig(time.Hour)
+	rotated.SecretRefs = running.SecretRefs
+	rotated.Webhooks[0].URL = "https://example.com/rotated"
+
+	next := running
+	var applied *config.Config
+	reloader := NewReloader(running,
+		func() (*config.Config, error) { copied := *next; return &copied, nil },
+		func(cfg *config.Config) error { applied = cfg; return nil },
+		logrus.NewEntry(logrus.New()))
+
+	reloader.refresh()
+	if applied != nil {
+		t.Error("Expected unchanged Secrets not to apply the configuration")
+	}
+
+	next = rotated
+	reloader.refresh()
+	if applied == nil || applied.Webhooks[0].URL != "https://example.com/rotated" {
+		t.Errorf("Expected the rotated webhook URL to be applied, got %+v", applied)
+	}
+}
@@ -0,0 +1,226 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/monitor"
+)
+
+// Output formats supported by Write
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// Sort keys supported by Sort
+const (
+	SortByExpiry    = "expiry"
+	SortByNamespace = "namespace"
+)
+
+// Row is a single certificate in a report
+type Row struct {
+	Cluster       string     `json:"cluster,omitempty"`
+	Namespace     string     `json:"namespace"`
+	Name          string     `json:"name"`
+	Issuer        string     `json:"issuer"`
+	State         string     `json:"state"`
+	Ready         bool       `json:"ready"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	DaysRemaining *int       `json:"days_remaining,omitempty"`
+	DNSNames      []string   `json:"dns_names"`
+}
+
+// headers are the column names used by the table, CSV and Markdown formats
+var headers = []string{"Namespace", "Name", "Issuer", "State", "Ready", "Expires At", "Days Remaining", "DNS Names"}
+
+// clusterHeader is the leading column added when reporting on several clusters
+const clusterHeader = "Cluster"
+
+// Filter returns the certificates expiring within the given duration.
+// A zero duration includes all certificates.
+func Filter(certificates []monitor.CertificateStatus, within time.Duration, now time.Time) []monitor.CertificateStatus {
+	if within <= 0 {
+		return certificates
+	}
+
+	filtered := make([]monitor.CertificateStatus, 0, len(certificates))
+	for _, cert := range certificates {
+		if cert.ExpiresAt != nil && !cert.ExpiresAt.After(now.Add(within)) {
+			filtered = append(filtered, cert)
+		}
+	}
+	return filtered
+}
+
+// Sort orders certificates by the given key. Certificates without an
+// expiration date are placed last when sorting by expiry.
+func Sort(certificates []monitor.CertificateStatus, by string) error {
+	switch by {
+	case SortByExpiry:
+		sort.SliceStable(certificates, func(i, j int) bool {
+			a, b := certificates[i].ExpiresAt, certificates[j].ExpiresAt
+			if a == nil || b == nil {
+				return a != nil
+			}
+			return a.Before(*b)
+		})
+	case SortByNamespace:
+		sort.SliceStable(certificates, func(i, j int) bool {
+			if certificates[i].Cluster != certificates[j].Cluster {
+				return certificates[i].Cluster < certificates[j].Cluster
+			}
+			if certificates[i].Namespace != certificates[j].Namespace {
+				return certificates[i].Namespace < certificates[j].Namespace
+			}
+			return certificates[i].Name < certificates[j].Name
+		})
+	default:
+		return fmt.Errorf("unknown sort key %q", by)
+	}
+	return nil
+}
+
+// Write writes the certificates in the given format
+func Write(w io.Writer, format string, certificates []monitor.CertificateStatus, now time.Time) error {
+	rows := make([]Row, 0, len(certificates))
+	withCluster := false
+	for _, cert := range certificates {
+		rows = append(rows, newRow(cert, now))
+		withCluster = withCluster || cert.Cluster != ""
+	}
+
+	columns := headers
+	if withCluster {
+		columns = append([]string{clusterHeader}, headers...)
+	}
+
+	switch format {
+	case FormatTable:
+		return writeTable(w, columns, rows)
+	case FormatJSON:
+		return writeJSON(w, rows)
+	case FormatCSV:
+		return writeCSV(w, columns, rows)
+	case FormatMarkdown:
+		return writeMarkdown(w, columns, rows)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// newRow converts a certificate status into a report row
+func newRow(cert monitor.CertificateStatus, now time.Time) Row {
+	row := Row{
+		Cluster:   cert.Cluster,
+		Namespace: cert.Namespace,
+		Name:      cert.Name,
+		Issuer:    cert.Issuer,
+		State:     cert.State,
+		Ready:     cert.Ready,
+		ExpiresAt: cert.ExpiresAt,
+		DNSNames:  cert.DNSNames,
+	}
+
+	if cert.ExpiresAt != nil {
+		days := int(cert.ExpiresAt.Sub(now).Hours() / 24)
+		row.DaysRemaining = &days
+	}
+
+	return row
+}
+
+// fields returns the row values in the order of columns
+func (r Row) fields(columns []string, dnsSeparator string) []string {
+	expiresAt, days := "-", "-"
+	if r.ExpiresAt != nil {
+		expiresAt = r.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if r.DaysRemaining != nil {
+		days = strconv.Itoa(*r.DaysRemaining)
+	}
+
+	fields := []string{
+		r.Namespace,
+		r.Name,
+		r.Issuer,
+		r.State,
+		strconv.FormatBool(r.Ready),
+		expiresAt,
+		days,
+		strings.Join(r.DNSNames, dnsSeparator),
+	}
+	if columns[0] == clusterHeader {
+		fields = append([]string{r.Cluster}, fields...)
+	}
+	return fields
+}
+
+// writeTable writes the rows as an aligned text table
+func writeTable(w io.Writer, columns []string, rows []Row) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row.fields(columns, ","), "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeJSON writes the rows as an indented JSON array
+func writeJSON(w io.Writer, rows []Row) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
+
+// writeCSV writes the rows as CSV with a header line
+func writeCSV(w io.Writer, columns []string, rows []Row) error {
+	cw := csv.NewWriter(w)
+	names := make([]string, len(columns))
+	for i, header := range columns {
+		names[i] = strings.ReplaceAll(strings.ToLower(header), " ", "_")
+	}
+	if err := cw.Write(names); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := cw.Write(row.fields(columns, ";")); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeMarkdown writes the rows as a Markdown table
+func writeMarkdown(w io.Writer, columns []string, rows []Row) error {
+	separators := make([]string, len(columns))
+	for i := range columns {
+		separators[i] = "---"
+	}
+
+	if _, err := fmt.Fprintf(w, "| %s |\n| %s |\n", strings.Join(columns, " | "), strings.Join(separators, " | ")); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		fields := row.fields(columns, ", ")
+		for i, field := range fields {
+			fields[i] = strings.ReplaceAll(field, "|", `\|`)
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(fields, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/monitor"
+)
+
+func testCertificates(now time.Time) []monitor.CertificateStatus {
+	soon := now.Add(10 * 24 * time.Hour)
+	later := now.Add(60 * 24 * time.Hour)
+
+	return []monitor.CertificateStatus{
+		{Namespace: "default", Name: "web", Issuer: "letsencrypt", State: monitor.StateOK, Ready: true, ExpiresAt: &later, DNSNames: []string{"example.com", "www.example.com"}},
+		{Namespace: "default", Name: "pending", Issuer: "letsencrypt", State: monitor.StateUnknown},
+		{Namespace: "api", Name: "api", Issuer: "internal-ca", State: monitor.StateExpiring, Ready: true, ExpiresAt: &soon, DNSNames: []string{"api.example.com"}},
+	}
+}
+
+func TestFilterAndSort(t *testing.T) {
+	now := time.Now()
+	certificates := testCertificates(now)
+
+	if err := Sort(certificates, SortByExpiry); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if certificates[0].Name != "api" || certificates[2].Name != "pending" {
+		t.Errorf("Expected certificates sorted by expiry with unknown last, got %s, %s, %s",
+			certificates[0].Name, certificates[1].Name, certificates[2].Name)
+	}
+
+	filtered := Filter(certificates, 30*24*time.Hour, now)
+	if len(filtered) != 1 || filtered[0].Name != "api" {
+		t.Errorf("Expected only the expiring certificate, got %+v", filtered)
+	}
+
+	if err := Sort(certificates, "size"); err == nil {
+		t.Error("Expected error for unknown sort key, got nil")
+	}
+}
+
+func TestWrite_JSON(t *testing.T) {
+	now := time.Now()
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, testCertificates(now), now); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var rows []Row
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatalf("Failed to decode JSON report: %v", err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(rows))
+	}
+
+	if rows[2].DaysRemaining == nil || *rows[2].DaysRemaining != 10 {
+		t.Errorf("Expected 10 days remaining, got %v", rows[2].DaysRemaining)
+	}
+}
+
+func TestWrite_CSV(t *testing.T) {
+	now := time.Now()
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, testCertificates(now), now); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV report: %v", err)
+	}
+
+	if len(records) != 4 {
+		t.Fatalf("Expected header and 3 records, got %d", len(records))
+	}
+
+	if records[0][6] != "days_remaining" {
+		t.Errorf("Expected 'days_remaining' column, got '%s'", records[0][6])
+	}
+
+	if records[1][7] != "example.com;www.example.com" {
+		t.Errorf("Expected DNS names joined by ';', got '%s'", records[1][7])
+	}
+}
+
+func TestWrite_CSVWithClusters(t *testing.T) {
+	now := time.Now()
+	certificates := testCertificates(now)
+	certificates[0].Cluster = "prod"
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, certificates, now); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV report: %v", err)
+	}
+
+	if records[0][0] != "cluster" || records[1][0] != "prod" || records[1][1] != "default" {
+		t.Errorf("Expected a leading cluster column, got %v and %v", records[0], records[1])
+	}
+}
+
+func TestWrite_TableAndMarkdown(t *testing.T) {
+	now := time.Now()
+
+	var table bytes.Buffer
+	if err := Write(&table, FormatTable, testCertificates(now), now); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.HasPrefix(table.String(), "NAMESPACE") {
+		t.Errorf("Expected table header, got: %s", table.String())
+	}
+
+	var markdown bytes.Buffer
+	if err := Write(&markdown, FormatMarkdown, testCertificates(now), now); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(markdown.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[1], "| --- |") {
+		t.Errorf("Unexpected markdown table:\n%s", markdown.String())
+	}
+
+	if err := Write(&markdown, "xml", nil, now); err == nil {
+		t.Error("Expected error for unknown format, got nil")
+	}
+}
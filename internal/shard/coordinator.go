@@ -0,0 +1,242 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LabelGroup labels the Leases of the replicas sharing the work
+const LabelGroup = "cert-manager-notifier.io/shard-group"
+
+// releaseTimeout bounds how long leaving the group may take on shutdown
+const releaseTimeout = 5 * time.Second
+
+// Coordinator keeps a Lease for this replica and divides keys between the
+// replicas whose Leases are current
+type Coordinator struct {
+	client        kubernetes.Interface
+	namespace     string
+	group         string
+	identity      string
+	leaseDuration time.Duration
+	logger        *logrus.Entry
+
+	mutex       sync.RWMutex
+	members     []string
+	ring        *Ring
+	lastError   error
+	subscribers []chan struct{}
+}
+
+// NewCoordinator creates a coordinator for the replica identity in group.
+// Until the first sync it owns every key.
+func NewCoordinator(client kubernetes.Interface, namespace, group, identity string, leaseDuration time.Duration, logger *logrus.Entry) *Coordinator {
+	return &Coordinator{
+		client:        client,
+		namespace:     namespace,
+		group:         group,
+		identity:      identity,
+		leaseDuration: leaseDuration,
+		logger:        logger.WithField("component", "shard-coordinator"),
+		members:       []string{identity},
+		ring:          NewRing([]string{identity}),
+	}
+}
+
+// Owns reports whether this replica is responsible for a key
+func (c *Coordinator) Owns(key string) bool {
+	return c.Owner(key) == c.identity
+}
+
+// Owner returns the replica responsible for a key
+func (c *Coordinator) Owner(key string) string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.ring.Owner(key)
+}
+
+// Members returns the replicas currently sharing the work
+func (c *Coordinator) Members() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return slices.Clone(c.members)
+}
+
+// Subscribe returns a channel that receives a value whenever the members change
+func (c *Coordinator) Subscribe() <-chan struct{} {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	changes := make(chan struct{}, 1)
+	c.subscribers = append(c.subscribers, changes)
+	return changes
+}
+
+// Status reports an error when the last attempt to sync the Leases failed
+func (c *Coordinator) Status() error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.lastError != nil {
+		return fmt.Errorf("failed to sync shard leases: %w", c.lastError)
+	}
+	return nil
+}
+
+// Run renews the Lease and refreshes the members until the context is
+// cancelled, then releases the Lease so the others take over immediately
+func (c *Coordinator) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.leaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			releaseCtx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+			if err := c.Release(releaseCtx); err != nil {
+				c.logger.WithError(err).Warn("Failed to release shard lease")
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := c.Sync(ctx); err != nil {
+				c.logger.WithError(err).Error("Failed to sync shard leases")
+			}
+		}
+	}
+}
+
+// Sync renews this replica's Lease and rebuilds the ring from the Leases
+// that have not expired
+func (c *Coordinator) Sync(ctx context.Context) error {
+	err := c.sync(ctx, time.Now())
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.lastError = err
+	return err
+}
+
+// sync renews the Lease, lists the members and updates the ring
+func (c *Coordinator) sync(ctx context.Context, now time.Time) error {
+	if err := c.renew(ctx, now); err != nil {
+		return err
+	}
+
+	leases, err := c.client.CoordinationV1().Leases(c.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: LabelGroup + "=" + c.group,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list leases: %w", err)
+	}
+
+	members := []string{c.identity}
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		holder := lease.Spec.HolderIdentity
+		if holder == nil || *holder == c.identity {
+			continue
+		}
+
+		expiresAt, ok := leaseExpiry(lease)
+		switch {
+		case ok && now.Before(expiresAt):
+			members = append(members, *holder)
+		case !ok || now.Sub(expiresAt) > 2*c.leaseDuration:
+			// Clean up after replicas that left without releasing their Lease
+			err := c.client.CoordinationV1().Leases(c.namespace).Delete(ctx, lease.Name, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				c.logger.WithError(err).WithField("lease", lease.Name).Debug("Failed to delete expired shard lease")
+			}
+		}
+	}
+	slices.Sort(members)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if slices.Equal(members, c.members) {
+		return nil
+	}
+
+	c.logger.WithField("members", members).Info("Shard members changed, rebalancing")
+	c.members = members
+	c.ring = NewRing(members)
+	for _, changes := range c.subscribers {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// renew creates or renews this replica's Lease
+func (c *Coordinator) renew(ctx context.Context, now time.Time) error {
+	leases := c.client.CoordinationV1().Leases(c.namespace)
+	renewTime := metav1.NewMicroTime(now)
+	durationSeconds := int32(c.leaseDuration.Seconds())
+
+	lease, err := leases.Get(ctx, c.leaseName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.leaseName(),
+				Namespace: c.namespace,
+				Labels:    map[string]string{LabelGroup: c.group},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &c.identity,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &renewTime,
+				RenewTime:            &renewTime,
+			},
+		}
+		if _, err := leases.Create(ctx, lease, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create lease: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get lease: %w", err)
+	}
+
+	lease.Spec.HolderIdentity = &c.identity
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+	lease.Spec.RenewTime = &renewTime
+	if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to renew lease: %w", err)
+	}
+	return nil
+}
+
+// Release deletes this replica's Lease
+func (c *Coordinator) Release(ctx context.Context) error {
+	err := c.client.CoordinationV1().Leases(c.namespace).Delete(ctx, c.leaseName(), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete lease: %w", err)
+	}
+	return nil
+}
+
+// leaseName names this replica's Lease
+func (c *Coordinator) leaseName() string {
+	return c.group + "-" + c.identity
+}
+
+// leaseExpiry returns when a Lease expires unless renewed
+func leaseExpiry(lease *coordinationv1.Lease) (time.Time, bool) {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return time.Time{}, false
+	}
+	return lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second), true
+}
@@ -0,0 +1,58 @@
+package shard
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// virtualNodes is the number of points each member has on the ring, which
+// spreads keys evenly and limits how many move when members join or leave
+const virtualNodes = 128
+
+// Ring assigns keys to members by consistent hashing
+type Ring struct {
+	points []uint64
+	owners map[uint64]string
+}
+
+// NewRing creates a ring of the given members
+func NewRing(members []string) *Ring {
+	ring := &Ring{owners: make(map[uint64]string, len(members)*virtualNodes)}
+
+	for _, member := range members {
+		for i := 0; i < virtualNodes; i++ {
+			point := hash(member + "#" + strconv.Itoa(i))
+			if _, taken := ring.owners[point]; taken {
+				continue
+			}
+			ring.owners[point] = member
+			ring.points = append(ring.points, point)
+		}
+	}
+
+	sort.Slice(ring.points, func(i, j int) bool { return ring.points[i] < ring.points[j] })
+	return ring
+}
+
+// Owner returns the member responsible for a key, or "" for an empty ring
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	point := hash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= point })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+// hash maps a string onto the ring. Similar keys such as namespace-1 and
+// namespace-2 must land far apart, which rules out simpler hashes like FNV.
+func hash(val string) uint64 {
+	sum := sha256.Sum256([]byte(val))
+	return binary.BigEndian.Uint64(sum[:8])
+}
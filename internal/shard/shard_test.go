@@ -0,0 +1,94 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRing_Rebalance(t *testing.T) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("namespace-%d", i)
+	}
+
+	before := NewRing([]string{"a", "b", "c"})
+	after := NewRing([]string{"a", "b", "c", "d"})
+
+	counts := make(map[string]int)
+	moved := 0
+	for _, key := range keys {
+		owner := after.Owner(key)
+		counts[owner]++
+		if previous := before.Owner(key); previous != owner {
+			if owner != "d" {
+				t.Errorf("Key %s moved from %s to %s instead of to the new member", key, previous, owner)
+			}
+			moved++
+		}
+	}
+
+	for _, member := range []string{"a", "b", "c", "d"} {
+		if counts[member] < 150 || counts[member] > 350 {
+			t.Errorf("Expected about 250 keys for %s, got %d", member, counts[member])
+		}
+	}
+	if moved > 350 {
+		t.Errorf("Expected about a quarter of the keys to move, got %d", moved)
+	}
+
+	if owner := NewRing(nil).Owner("key"); owner != "" {
+		t.Errorf("Expected no owner on an empty ring, got %q", owner)
+	}
+}
+
+func TestCoordinator_Sync(t *testing.T) {
+	ctx := context.Background()
+	client := kubefake.NewSimpleClientset()
+	logger := logrus.NewEntry(logrus.New())
+
+	first := NewCoordinator(client, "default", "notifier", "replica-1", 30*time.Second, logger)
+	second := NewCoordinator(client, "default", "notifier", "replica-2", 30*time.Second, logger)
+
+	if !first.Owns("any") {
+		t.Error("Expected a coordinator to own every key before the first sync")
+	}
+
+	changes := first.Subscribe()
+	for _, coordinator := range []*Coordinator{first, second, first} {
+		if err := coordinator.Sync(ctx); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	select {
+	case <-changes:
+	default:
+		t.Error("Expected a notification of the member change")
+	}
+
+	if members := first.Members(); len(members) != 2 {
+		t.Fatalf("Expected 2 members, got %v", members)
+	}
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("namespace-%d", i)
+		if first.Owns(key) == second.Owns(key) {
+			t.Errorf("Expected exactly one replica to own %s", key)
+		}
+	}
+
+	if err := second.Release(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := first.Sync(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if members := first.Members(); len(members) != 1 || members[0] != "replica-1" {
+		t.Errorf("Expected only replica-1 after the other left, got %v", members)
+	}
+}
@@ -0,0 +1,108 @@
+package silence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// ConfigMapKey is the ConfigMap key holding the silences as JSON
+const ConfigMapKey = "silences.json"
+
+// Backend persists silences outside of the process
+type Backend interface {
+	// Load returns the persisted silences
+	Load(ctx context.Context) ([]Silence, error)
+
+	// Update replaces the persisted silences with those returned by update
+	Update(ctx context.Context, update func([]Silence) []Silence) error
+}
+
+// ConfigMap persists silences in a ConfigMap, so that they survive restarts
+// and are shared by every replica
+type ConfigMap struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// NewConfigMap creates a backend keeping silences in the named ConfigMap,
+// which is created on the first write
+func NewConfigMap(client kubernetes.Interface, namespace, name string) *ConfigMap {
+	return &ConfigMap{client: client, namespace: namespace, name: name}
+}
+
+// Load returns the silences in the ConfigMap, or none if it does not exist
+func (c *ConfigMap) Load(ctx context.Context) ([]Silence, error) {
+	configMap, err := c.client.CoreV1().ConfigMaps(c.namespace).Get(ctx, c.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", c.namespace, c.name, err)
+	}
+	return c.decode(configMap)
+}
+
+// Update reads the silences, replaces them with those returned by update and
+// writes them back, retrying when another replica wrote in between
+func (c *ConfigMap) Update(ctx context.Context, update func([]Silence) []Silence) error {
+	configMaps := c.client.CoreV1().ConfigMaps(c.namespace)
+
+	conflict := func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, conflict, func() error {
+		configMap, err := configMaps.Get(ctx, c.name, metav1.GetOptions{})
+		create := apierrors.IsNotFound(err)
+		if create {
+			configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: c.name, Namespace: c.namespace}}
+		} else if err != nil {
+			return fmt.Errorf("failed to get ConfigMap %s/%s: %w", c.namespace, c.name, err)
+		}
+
+		silences, err := c.decode(configMap)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(update(silences))
+		if err != nil {
+			return fmt.Errorf("failed to encode silences: %w", err)
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[ConfigMapKey] = string(data)
+
+		if create {
+			_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+		} else {
+			_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+		}
+		if err != nil && !conflict(err) {
+			return fmt.Errorf("failed to write ConfigMap %s/%s: %w", c.namespace, c.name, err)
+		}
+		return err
+	})
+}
+
+// decode parses the silences stored in a ConfigMap
+func (c *ConfigMap) decode(configMap *corev1.ConfigMap) ([]Silence, error) {
+	data := configMap.Data[ConfigMapKey]
+	if data == "" {
+		return nil, nil
+	}
+
+	var silences []Silence
+	if err := json.Unmarshal([]byte(data), &silences); err != nil {
+		return nil, fmt.Errorf("invalid %s in ConfigMap %s/%s: %w", ConfigMapKey, c.namespace, c.name, err)
+	}
+	return silences, nil
+}
@@ -0,0 +1,111 @@
+package silence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPersistentStore(t *testing.T) {
+	ctx := context.Background()
+	client := kubefake.NewSimpleClientset()
+	now := time.Now()
+
+	// Two replicas sharing the ConfigMap
+	first := NewPersistentStore(NewConfigMap(client, "monitoring", "silences"))
+	second := NewPersistentStore(NewConfigMap(client, "monitoring", "silences"))
+
+	expired, err := first.Add(ctx, Silence{
+		Matchers:  []Matcher{{Name: MatcherName, Value: "old"}},
+		Author:    "alice",
+		ExpiresAt: now.Add(time.Minute),
+	}, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	created, err := first.Add(ctx, Silence{
+		Matchers:  []Matcher{{Name: MatcherNamespace, Value: "default"}},
+		Author:    "alice",
+		ExpiresAt: now.Add(time.Hour),
+	}, now.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if err := second.Sync(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if silence, ok := second.Match(Target{Namespace: "default", Name: "web"}, now); !ok || silence.ID != created.ID {
+		t.Errorf("Expected the silence added on the other replica to match, got %+v", silence)
+	}
+
+	// Writes drop the silences that have expired
+	if _, ok := second.Match(Target{Name: "old"}, now); ok {
+		t.Errorf("Expected expired silence %s to be dropped from the ConfigMap", expired.ID)
+	}
+
+	// A restarted replica loads the silences
+	restarted := NewPersistentStore(NewConfigMap(client, "monitoring", "silences"))
+	if err := restarted.Sync(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if silences := restarted.List(now); len(silences) != 1 || silences[0].ID != created.ID {
+		t.Errorf("Expected the silence to survive a restart, got %+v", silences)
+	}
+
+	// A silence deleted on one replica is gone on the others after a sync
+	if deleted, err := second.Delete(ctx, created.ID); err != nil || !deleted {
+		t.Fatalf("Expected the silence to be deleted, got %v, %v", deleted, err)
+	}
+	if err := first.Sync(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, ok := first.Match(Target{Namespace: "default"}, now); ok {
+		t.Error("Expected the deleted silence not to match")
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps("monitoring").Get(ctx, "silences", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the ConfigMap to exist, got: %v", err)
+	}
+	if data := configMap.Data[ConfigMapKey]; data != "[]" {
+		t.Errorf("Expected no silences left in the ConfigMap, got %s", data)
+	}
+}
+
+func TestPersistentStore_InvalidConfigMap(t *testing.T) {
+	ctx := context.Background()
+	client := kubefake.NewSimpleClientset()
+	store := NewPersistentStore(NewConfigMap(client, "monitoring", "silences"))
+
+	if err := store.Sync(ctx); err != nil {
+		t.Fatalf("Expected a missing ConfigMap to hold no silences, got: %v", err)
+	}
+
+	_, err := client.CoreV1().ConfigMaps("monitoring").Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "silences", Namespace: "monitoring"},
+		Data:       map[string]string{ConfigMapKey: "not json"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create ConfigMap: %v", err)
+	}
+
+	if err := store.Sync(ctx); err == nil {
+		t.Error("Expected an error for invalid silences, got nil")
+	}
+
+	// Invalid silences are not overwritten
+	_, err = store.Add(ctx, Silence{
+		Matchers:  []Matcher{{Name: MatcherName, Value: "x"}},
+		Author:    "alice",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, time.Now())
+	if err == nil {
+		t.Error("Expected an error adding to invalid silences, got nil")
+	}
+}
@@ -0,0 +1,308 @@
+package silence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Annotations that silence a certificate directly on the Certificate resource
+const (
+	AnnotationSilencedUntil  = "cert-manager-notifier.io/silenced-until"
+	AnnotationSilencedBy     = "cert-manager-notifier.io/silenced-by"
+	AnnotationSilenceComment = "cert-manager-notifier.io/silence-comment"
+)
+
+// Matcher names supported by silences
+const (
+	MatcherCluster   = "cluster"
+	MatcherNamespace = "namespace"
+	MatcherName      = "name"
+	MatcherIssuer    = "issuer"
+	MatcherDNSName   = "dns_name"
+)
+
+// Matcher matches a single certificate attribute
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"is_regex"`
+}
+
+// Silence suppresses notifications for certificates matching all of its matchers
+type Silence struct {
+	ID        string    `json:"id"`
+	Matchers  []Matcher `json:"matchers"`
+	Author    string    `json:"author"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Target holds the certificate attributes matchers are evaluated against
+type Target struct {
+	Cluster   string
+	Namespace string
+	Name      string
+	Issuer    string
+	DNSNames  []string
+}
+
+// Validate checks that the silence is well-formed
+func (s *Silence) Validate(now time.Time) error {
+	if len(s.Matchers) == 0 {
+		return fmt.Errorf("at least one matcher is required")
+	}
+	if s.Author == "" {
+		return fmt.Errorf("author is required")
+	}
+	if !s.ExpiresAt.After(now) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+
+	for _, matcher := range s.Matchers {
+		switch matcher.Name {
+		case MatcherCluster, MatcherNamespace, MatcherName, MatcherIssuer, MatcherDNSName:
+		default:
+			return fmt.Errorf("unknown matcher name %q", matcher.Name)
+		}
+		if matcher.IsRegex {
+			if _, err := regexp.Compile(matcher.Value); err != nil {
+				return fmt.Errorf("invalid regex for matcher %q: %w", matcher.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Active reports whether the silence is in effect at the given time
+func (s *Silence) Active(now time.Time) bool {
+	return now.Before(s.ExpiresAt)
+}
+
+// Matches reports whether all matchers match the target
+func (s *Silence) Matches(target Target) bool {
+	for _, matcher := range s.Matchers {
+		if !matcher.matches(target) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether the matcher matches the target
+func (m Matcher) matches(target Target) bool {
+	switch m.Name {
+	case MatcherCluster:
+		return m.matchValue(target.Cluster)
+	case MatcherNamespace:
+		return m.matchValue(target.Namespace)
+	case MatcherName:
+		return m.matchValue(target.Name)
+	case MatcherIssuer:
+		return m.matchValue(target.Issuer)
+	case MatcherDNSName:
+		for _, dnsName := range target.DNSNames {
+			if m.matchValue(dnsName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchValue compares a single value against the matcher
+func (m Matcher) matchValue(value string) bool {
+	if !m.IsRegex {
+		return m.Value == value
+	}
+
+	re, err := regexp.Compile("^(?:" + m.Value + ")$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+// FromAnnotations builds a silence from Certificate annotations.
+// It returns nil if the certificate carries no silence annotation.
+func FromAnnotations(annotations map[string]string) (*Silence, error) {
+	until, ok := annotations[AnnotationSilencedUntil]
+	if !ok || until == "" {
+		return nil, nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, until)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", AnnotationSilencedUntil, err)
+	}
+
+	author := annotations[AnnotationSilencedBy]
+	if author == "" {
+		author = "annotation"
+	}
+
+	return &Silence{
+		ID:        "annotation",
+		Author:    author,
+		Comment:   annotations[AnnotationSilenceComment],
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Store holds silences in memory, optionally persisting them in a backend
+type Store struct {
+	silences map[string]Silence
+	backend  Backend
+	mutex    sync.RWMutex
+}
+
+// NewStore creates a new silence store
+func NewStore() *Store {
+	return &Store{
+		silences: make(map[string]Silence),
+	}
+}
+
+// NewPersistentStore creates a silence store writing its silences through to
+// backend. The silences in the backend are picked up on Sync.
+func NewPersistentStore(backend Backend) *Store {
+	store := NewStore()
+	store.backend = backend
+	return store
+}
+
+// Sync replaces the silences in memory with those in the backend, including
+// silences added or deleted by other replicas. On failure the silences in
+// memory are kept.
+func (s *Store) Sync(ctx context.Context) error {
+	if s.backend == nil {
+		return nil
+	}
+
+	silences, err := s.backend.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load silences: %w", err)
+	}
+
+	loaded := make(map[string]Silence, len(silences))
+	for _, silence := range silences {
+		loaded[silence.ID] = silence
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.silences = loaded
+	return nil
+}
+
+// Add validates and stores a silence, assigning it a new ID
+func (s *Store) Add(ctx context.Context, silence Silence, now time.Time) (Silence, error) {
+	if err := silence.Validate(now); err != nil {
+		return Silence{}, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Silence{}, fmt.Errorf("failed to generate silence ID: %w", err)
+	}
+
+	silence.ID = id
+	silence.CreatedAt = now
+
+	if s.backend != nil {
+		err := s.backend.Update(ctx, func(silences []Silence) []Silence {
+			return append(active(silences, now), silence)
+		})
+		if err != nil {
+			return Silence{}, fmt.Errorf("failed to persist silence: %w", err)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.silences[id] = silence
+
+	return silence, nil
+}
+
+// List returns all active silences ordered by expiry and drops expired ones
+func (s *Store) List(now time.Time) []Silence {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	silences := make([]Silence, 0, len(s.silences))
+	for id, silence := range s.silences {
+		if !silence.Active(now) {
+			delete(s.silences, id)
+			continue
+		}
+		silences = append(silences, silence)
+	}
+
+	sort.Slice(silences, func(i, j int) bool {
+		return silences[i].ExpiresAt.Before(silences[j].ExpiresAt)
+	})
+
+	return silences
+}
+
+// Delete removes a silence and reports whether it existed
+func (s *Store) Delete(ctx context.Context, id string) (bool, error) {
+	persisted := false
+	if s.backend != nil {
+		err := s.backend.Update(ctx, func(silences []Silence) []Silence {
+			remaining := slices.DeleteFunc(silences, func(silence Silence) bool {
+				return silence.ID == id
+			})
+			persisted = len(remaining) < len(silences)
+			return remaining
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to delete persisted silence: %w", err)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, exists := s.silences[id]
+	delete(s.silences, id)
+	return exists || persisted, nil
+}
+
+// Match returns the first active silence matching the target
+func (s *Store) Match(target Target, now time.Time) (Silence, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, silence := range s.silences {
+		if silence.Active(now) && silence.Matches(target) {
+			return silence, true
+		}
+	}
+	return Silence{}, false
+}
+
+// active returns the silences that have not expired
+func active(silences []Silence, now time.Time) []Silence {
+	return slices.DeleteFunc(silences, func(silence Silence) bool {
+		return !silence.Active(now)
+	})
+}
+
+// newID generates a random silence ID
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
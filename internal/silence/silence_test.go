@@ -0,0 +1,127 @@
+package silence
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStore_AddAndMatch(t *testing.T) {
+	store := NewStore()
+	now := time.Now()
+
+	created, err := store.Add(context.Background(), Silence{
+		Matchers: []Matcher{
+			{Name: MatcherNamespace, Value: "default"},
+			{Name: MatcherName, Value: "api-.*", IsRegex: true},
+		},
+		Author:    "alice",
+		Comment:   "renewal in progress",
+		ExpiresAt: now.Add(time.Hour),
+	}, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if created.ID == "" {
+		t.Error("Expected silence ID to be assigned")
+	}
+
+	if _, ok := store.Match(Target{Namespace: "default", Name: "api-cert"}, now); !ok {
+		t.Error("Expected certificate to be silenced")
+	}
+
+	if _, ok := store.Match(Target{Namespace: "other", Name: "api-cert"}, now); ok {
+		t.Error("Expected certificate in other namespace not to be silenced")
+	}
+
+	if _, ok := store.Match(Target{Namespace: "default", Name: "api-cert"}, now.Add(2*time.Hour)); ok {
+		t.Error("Expected expired silence not to match")
+	}
+}
+
+func TestStore_MatchDNSName(t *testing.T) {
+	store := NewStore()
+	now := time.Now()
+
+	_, err := store.Add(context.Background(), Silence{
+		Matchers:  []Matcher{{Name: MatcherDNSName, Value: "www.example.com"}},
+		Author:    "alice",
+		ExpiresAt: now.Add(time.Hour),
+	}, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, ok := store.Match(Target{DNSNames: []string{"example.com", "www.example.com"}}, now); !ok {
+		t.Error("Expected certificate with matching DNS name to be silenced")
+	}
+}
+
+func TestStore_AddInvalid(t *testing.T) {
+	store := NewStore()
+	now := time.Now()
+
+	tests := map[string]Silence{
+		"no matchers":     {Author: "alice", ExpiresAt: now.Add(time.Hour)},
+		"no author":       {Matchers: []Matcher{{Name: MatcherName, Value: "x"}}, ExpiresAt: now.Add(time.Hour)},
+		"already expired": {Matchers: []Matcher{{Name: MatcherName, Value: "x"}}, Author: "alice", ExpiresAt: now.Add(-time.Hour)},
+		"unknown matcher": {Matchers: []Matcher{{Name: "label", Value: "x"}}, Author: "alice", ExpiresAt: now.Add(time.Hour)},
+		"invalid regex":   {Matchers: []Matcher{{Name: MatcherName, Value: "(", IsRegex: true}}, Author: "alice", ExpiresAt: now.Add(time.Hour)},
+	}
+
+	for name, s := range tests {
+		if _, err := store.Add(context.Background(), s, now); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+func TestStore_ListAndDelete(t *testing.T) {
+	store := NewStore()
+	now := time.Now()
+
+	created, err := store.Add(context.Background(), Silence{
+		Matchers:  []Matcher{{Name: MatcherName, Value: "x"}},
+		Author:    "alice",
+		ExpiresAt: now.Add(time.Hour),
+	}, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(store.List(now)) != 1 {
+		t.Errorf("Expected 1 silence, got %d", len(store.List(now)))
+	}
+
+	if len(store.List(now.Add(2*time.Hour))) != 0 {
+		t.Error("Expected expired silence to be dropped from list")
+	}
+
+	if deleted, err := store.Delete(context.Background(), created.ID); err != nil || deleted {
+		t.Errorf("Expected expired silence to have been removed already, got %v, %v", deleted, err)
+	}
+}
+
+func TestFromAnnotations(t *testing.T) {
+	s, err := FromAnnotations(map[string]string{
+		AnnotationSilencedUntil:  "2030-01-01T00:00:00Z",
+		AnnotationSilencedBy:     "bob",
+		AnnotationSilenceComment: "migrating issuer",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if s == nil || s.Author != "bob" || s.Comment != "migrating issuer" {
+		t.Errorf("Unexpected silence from annotations: %+v", s)
+	}
+
+	if s, err := FromAnnotations(map[string]string{}); err != nil || s != nil {
+		t.Errorf("Expected no silence without annotations, got %+v, %v", s, err)
+	}
+
+	if _, err := FromAnnotations(map[string]string{AnnotationSilencedUntil: "tomorrow"}); err == nil {
+		t.Error("Expected error for invalid timestamp, got nil")
+	}
+}
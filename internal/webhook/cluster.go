@@ -0,0 +1,17 @@
+package webhook
+
+import "context"
+
+// clusterKey is the context key holding the name of the cluster notifications are about
+type clusterKey struct{}
+
+// WithCluster returns a context whose notifications are tagged with the cluster name
+func WithCluster(ctx context.Context, cluster string) context.Context {
+	return context.WithValue(ctx, clusterKey{}, cluster)
+}
+
+// clusterFromContext returns the cluster name set by WithCluster, if any
+func clusterFromContext(ctx context.Context) string {
+	cluster, _ := ctx.Value(clusterKey{}).(string)
+	return cluster
+}
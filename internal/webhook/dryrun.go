@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+// dryRunRecord is a rendered request written to the dry-run output
+type dryRunRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	RenderedRequest
+}
+
+// EnableDryRun makes the notifier render notifications instead of sending
+// them. Rendered requests are always logged and additionally written as JSON
+// lines to output if it is not nil.
+func (n *Notifier) EnableDryRun(output io.Writer) {
+	n.dryRun = true
+	n.dryRunOutput = output
+}
+
+// Redacted returns a copy of the request with secrets in the URL and headers redacted
+func (r RenderedRequest) Redacted() RenderedRequest {
+	headers := make(http.Header, len(r.Headers))
+	for key, values := range r.Headers {
+		if config.IsSensitiveHeader(key) {
+			values = []string{config.Redacted}
+		}
+		headers[key] = values
+	}
+
+	r.URL = config.RedactURL(r.URL)
+	r.Headers = headers
+	return r
+}
+
+// recordDryRun renders the notification for every webhook and records it
+// in the delivery history without sending it
+func (n *Notifier) recordDryRun(payload NotificationPayload, webhooks []config.WebhookConfig) error {
+	rendered, err := renderRequests(payload, webhooks)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, request := range rendered {
+		request = request.Redacted()
+
+		n.logger.WithField("webhook", request.Webhook).
+			WithField("url", request.URL).
+			WithField("headers", request.Headers).
+			WithField("body", string(request.Body)).
+			Info("Dry run: notification not sent")
+
+		if n.dryRunOutput != nil {
+			if err := n.writeDryRun(dryRunRecord{Timestamp: now, RenderedRequest: request}); err != nil {
+				return fmt.Errorf("failed to write dry run output: %w", err)
+			}
+		}
+
+		n.history.Add(Delivery{
+			Webhook:   request.Webhook,
+			Type:      payload.Type,
+			Cluster:   payload.Cluster,
+			Namespace: payload.Certificate.Namespace,
+			Name:      payload.Certificate.Name,
+			Success:   true,
+			DryRun:    true,
+			Timestamp: now,
+		})
+	}
+
+	return nil
+}
+
+// writeDryRun appends a record to the dry-run output
+func (n *Notifier) writeDryRun(record dryRunRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	n.dryRunMutex.Lock()
+	defer n.dryRunMutex.Unlock()
+	_, err = n.dryRunOutput.Write(append(line, '\n'))
+	return err
+}
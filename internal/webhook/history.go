@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+)
+
+// Delivery records the outcome of sending a notification to a webhook
+type Delivery struct {
+	Webhook    string    `json:"webhook"`
+	Type       string    `json:"type"`
+	Cluster    string    `json:"cluster,omitempty"`
+	Namespace  string    `json:"namespace"`
+	Name       string    `json:"name"`
+	Success    bool      `json:"success"`
+	DryRun     bool      `json:"dry_run,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// History keeps the most recent deliveries in memory
+type History struct {
+	deliveries []Delivery
+	next       int
+	full       bool
+	mutex      sync.RWMutex
+}
+
+// NewHistory creates a history holding up to size deliveries
+func NewHistory(size int) *History {
+	return &History{
+		deliveries: make([]Delivery, size),
+	}
+}
+
+// Add records a delivery, evicting the oldest one when full
+func (h *History) Add(delivery Delivery) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.deliveries) == 0 {
+		return
+	}
+
+	h.deliveries[h.next] = delivery
+	h.next = (h.next + 1) % len(h.deliveries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// List returns the recorded deliveries, newest first
+func (h *History) List() []Delivery {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	count := h.next
+	if h.full {
+		count = len(h.deliveries)
+	}
+
+	deliveries := make([]Delivery, 0, count)
+	for i := 0; i < count; i++ {
+		index := (h.next - 1 - i + len(h.deliveries)) % len(h.deliveries)
+		deliveries = append(deliveries, h.deliveries[index])
+	}
+	return deliveries
+}
+
+// LastByWebhook returns the most recent delivery per webhook for a certificate
+func (h *History) LastByWebhook(cluster, namespace, name string) map[string]Delivery {
+	last := make(map[string]Delivery)
+	for _, delivery := range h.List() {
+		if delivery.Cluster != cluster || delivery.Namespace != namespace || delivery.Name != name {
+			continue
+		}
+		if _, exists := last[delivery.Webhook]; !exists {
+			last[delivery.Webhook] = delivery
+		}
+	}
+	return last
+}
@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"testing"
+)
+
+func TestHistory_List(t *testing.T) {
+	history := NewHistory(2)
+
+	history.Add(Delivery{Webhook: "webhook-1", Name: "first"})
+	history.Add(Delivery{Webhook: "webhook-1", Name: "second"})
+	history.Add(Delivery{Webhook: "webhook-1", Name: "third"})
+
+	deliveries := history.List()
+	if len(deliveries) != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d", len(deliveries))
+	}
+
+	if deliveries[0].Name != "third" || deliveries[1].Name != "second" {
+		t.Errorf("Expected newest deliveries first, got %+v", deliveries)
+	}
+}
+
+func TestHistory_LastByWebhook(t *testing.T) {
+	history := NewHistory(10)
+
+	history.Add(Delivery{Webhook: "webhook-1", Namespace: "default", Name: "cert", Type: "expiring"})
+	history.Add(Delivery{Webhook: "webhook-2", Namespace: "default", Name: "cert", Type: "expiring"})
+	history.Add(Delivery{Webhook: "webhook-1", Namespace: "default", Name: "cert", Type: "expired"})
+	history.Add(Delivery{Webhook: "webhook-1", Namespace: "default", Name: "other", Type: "expiring"})
+	history.Add(Delivery{Webhook: "webhook-1", Cluster: "prod", Namespace: "default", Name: "cert", Type: "expiring"})
+
+	last := history.LastByWebhook("", "default", "cert")
+	if len(last) != 2 {
+		t.Fatalf("Expected 2 webhooks, got %d", len(last))
+	}
+
+	if last["webhook-1"].Type != "expired" {
+		t.Errorf("Expected last delivery to webhook-1 to be 'expired', got '%s'", last["webhook-1"].Type)
+	}
+}
@@ -4,34 +4,149 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/wiruzman/cert-manager-notifier/internal/config"
+	"github.com/wiruzman/cert-manager-notifier/internal/metrics"
 )
 
 // NotificationPayload represents the webhook notification payload
 type NotificationPayload struct {
 	Type        string `json:"type"`
 	Message     string `json:"message"`
+	Source      string `json:"source,omitempty"`
+	Cluster     string `json:"cluster,omitempty"`
 	Certificate struct {
-		Name      string    `json:"name"`
-		Namespace string    `json:"namespace"`
-		Issuer    string    `json:"issuer"`
-		DNSNames  []string  `json:"dns_names"`
-		ExpiresAt time.Time `json:"expires_at"`
+		Name         string    `json:"name"`
+		Namespace    string    `json:"namespace"`
+		Issuer       string    `json:"issuer"`
+		DNSNames     []string  `json:"dns_names"`
+		ExpiresAt    time.Time `json:"expires_at"`
+		SerialNumber string    `json:"serial_number,omitempty"`
 	} `json:"certificate"`
-	Timestamp time.Time `json:"timestamp"`
+	ChainElement         *ChainElement    `json:"chain_element,omitempty"`
+	Issuer               *IssuerDetails   `json:"issuer,omitempty"`
+	AffectedCertificates []CertificateRef `json:"affected_certificates,omitempty"`
+	ReferencedBy         []string         `json:"referenced_by,omitempty"`
+	Endpoints            []EndpointStatus `json:"endpoints,omitempty"`
+	Renewal              *RenewalDetails  `json:"renewal,omitempty"`
+	Details              []string         `json:"details,omitempty"`
+	Timestamp            time.Time        `json:"timestamp"`
+}
+
+// EndpointStatus is the certificate served by a probed TLS endpoint
+type EndpointStatus struct {
+	Address      string     `json:"address"`
+	ServerName   string     `json:"server_name"`
+	SerialNumber string     `json:"serial_number,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Stale        bool       `json:"stale"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// IssuerDetails describes the Issuer or ClusterIssuer an issuer notification is about
+type IssuerDetails struct {
+	Kind        string     `json:"kind"`
+	Name        string     `json:"name"`
+	Namespace   string     `json:"namespace,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
+	Message     string     `json:"message,omitempty"`
+	CASecret    string     `json:"ca_secret,omitempty"`
+	CAExpiresAt *time.Time `json:"ca_expires_at,omitempty"`
+}
+
+// String names the issuer, e.g. "ClusterIssuer letsencrypt" or "Issuer default/internal-ca"
+func (i IssuerDetails) String() string {
+	if i.Namespace == "" {
+		return fmt.Sprintf("%s %s", i.Kind, i.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", i.Kind, i.Namespace, i.Name)
+}
+
+// CertificateRef identifies a Certificate affected by an issuer problem
+type CertificateRef struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// ChainElement identifies the certificate in a TLS Secret's chain that
+// expires before the leaf
+type ChainElement struct {
+	Description  string    `json:"description"`
+	Role         string    `json:"role"`
+	Source       string    `json:"source"`
+	Position     int       `json:"position"`
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	NotAfter     time.Time `json:"not_after"`
+	SerialNumber string    `json:"serial_number"`
+}
+
+// Notification types
+const (
+	TypeExpired                    = "expired"
+	TypeExpiring                   = "expiring"
+	TypeSecretMismatch             = "secret_mismatch"
+	TypeServedStale                = "served_stale"
+	TypeServedExpiring             = "served_expiring"
+	TypeIssuerNotReady             = "issuer_not_ready"
+	TypeIssuerCAExpired            = "issuer_ca_expired"
+	TypeIssuerCAExpiring           = "issuer_ca_expiring"
+	TypeRenewalFailed              = "renewal_failed"
+	TypePolicyViolation            = "policy_violation"
+	TypeRenewalWindowMisconfigured = "renewal_window_misconfigured"
+)
+
+// Sources of the certificate a notification is about
+const (
+	// SourceCertificate is a cert-manager Certificate
+	SourceCertificate = "certificate"
+	// SourceUnmanagedSecret is a TLS Secret referenced by an Ingress or
+	// Gateway that is not managed by cert-manager
+	SourceUnmanagedSecret = "unmanaged_secret"
+)
+
+// maxResponseBodySize limits how much of a webhook response body is kept
+const maxResponseBodySize = 4096
+
+// deliveryFailureTTL is how long a failed delivery counts against the
+// notifier's readiness. Notifications are deduplicated, so the next delivery
+// can be a day away and must not be waited for to recover.
+const deliveryFailureTTL = 15 * time.Minute
+
+// deliveryFailure is the last failed delivery to a webhook
+type deliveryFailure struct {
+	err error
+	at  time.Time
 }
 
 // Notifier handles webhook notifications
 type Notifier struct {
-	webhooks []config.WebhookConfig
-	client   *http.Client
-	logger   *logrus.Entry
+	webhooks      []config.WebhookConfig
+	webhooksMutex sync.RWMutex
+	client        *http.Client
+	tlsClients    map[tlsClientKey]*http.Client
+	tlsMutex      sync.Mutex
+	logger        *logrus.Entry
+	history       *History
+
+	dryRun       bool
+	dryRunOutput io.Writer
+	dryRunMutex  sync.Mutex
+
+	lastFailures map[string]deliveryFailure
+	statusMutex  sync.RWMutex
 }
 
 // NewNotifier creates a new webhook notifier
@@ -41,14 +156,126 @@ func NewNotifier(webhooks []config.WebhookConfig, logger *logrus.Entry) *Notifie
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		logger: logger.WithField("component", "webhook-notifier"),
+		tlsClients:   make(map[tlsClientKey]*http.Client),
+		logger:       logger.WithField("component", "webhook-notifier"),
+		history:      NewHistory(500),
+		lastFailures: make(map[string]deliveryFailure),
 	}
 }
 
+// Webhooks returns the configured webhooks
+func (n *Notifier) Webhooks() []config.WebhookConfig {
+	n.webhooksMutex.RLock()
+	defer n.webhooksMutex.RUnlock()
+	return slices.Clone(n.webhooks)
+}
+
+// SetWebhooks replaces the configured webhooks for the notifications sent
+// from now on, dropping the TLS clients of those removed or changed
+func (n *Notifier) SetWebhooks(webhooks []config.WebhookConfig) {
+	n.webhooksMutex.Lock()
+	n.webhooks = slices.Clone(webhooks)
+	n.webhooksMutex.Unlock()
+
+	n.pruneClients(webhooks)
+}
+
+// History returns the recent delivery history
+func (n *Notifier) History() *History {
+	return n.history
+}
+
 // SendExpiredNotification sends a notification for expired certificates
 func (n *Notifier) SendExpiredNotification(ctx context.Context, certName, namespace, issuer string, dnsNames []string, expiresAt time.Time) error {
+	return n.sendNotification(ctx, newExpiredPayload(certName, namespace, issuer, dnsNames, expiresAt))
+}
+
+// SendExpiringNotification sends a notification for certificates expiring soon
+func (n *Notifier) SendExpiringNotification(ctx context.Context, certName, namespace, issuer string, dnsNames []string, expiresAt time.Time) error {
+	return n.sendNotification(ctx, newExpiringPayload(certName, namespace, issuer, dnsNames, expiresAt))
+}
+
+// SendChainExpiredNotification sends a notification for certificates whose
+// chain contains an expired intermediate or CA certificate
+func (n *Notifier) SendChainExpiredNotification(ctx context.Context, certName, namespace, issuer string, dnsNames []string, element ChainElement) error {
+	return n.sendNotification(ctx, newChainExpiredPayload(certName, namespace, issuer, dnsNames, element))
+}
+
+// SendChainExpiringNotification sends a notification for certificates whose
+// chain contains an intermediate or CA certificate expiring soon
+func (n *Notifier) SendChainExpiringNotification(ctx context.Context, certName, namespace, issuer string, dnsNames []string, element ChainElement) error {
+	return n.sendNotification(ctx, newChainExpiringPayload(certName, namespace, issuer, dnsNames, element))
+}
+
+// SendIssuerNotReadyNotification sends a notification for an issuer that is
+// not Ready, listing the certificates that will fail to renew
+func (n *Notifier) SendIssuerNotReadyNotification(ctx context.Context, issuer IssuerDetails, affected []CertificateRef) error {
+	return n.sendNotification(ctx, newIssuerNotReadyPayload(issuer, affected))
+}
+
+// SendIssuerCAExpiredNotification sends a notification for a CA issuer whose CA certificate has expired
+func (n *Notifier) SendIssuerCAExpiredNotification(ctx context.Context, issuer IssuerDetails, affected []CertificateRef) error {
+	return n.sendNotification(ctx, newIssuerCAExpiredPayload(issuer, affected))
+}
+
+// SendIssuerCAExpiringNotification sends a notification for a CA issuer whose CA certificate expires soon
+func (n *Notifier) SendIssuerCAExpiringNotification(ctx context.Context, issuer IssuerDetails, affected []CertificateRef) error {
+	return n.sendNotification(ctx, newIssuerCAExpiringPayload(issuer, affected))
+}
+
+// SendRenewalFailedNotification sends a notification for certificates that
+// are failing to renew, including what cert-manager reported about the attempt
+func (n *Notifier) SendRenewalFailedNotification(ctx context.Context, certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, renewal RenewalDetails) error {
+	return n.sendNotification(ctx, newRenewalFailedPayload(certName, namespace, issuer, dnsNames, expiresAt, renewal))
+}
+
+// SendUnmanagedExpiredNotification sends a notification for an expired
+// certificate in a TLS Secret that is not managed by cert-manager
+func (n *Notifier) SendUnmanagedExpiredNotification(ctx context.Context, secretName, namespace, issuer string, dnsNames []string, expiresAt time.Time, serialNumber string, referencedBy []string) error {
+	return n.sendNotification(ctx, newUnmanagedExpiredPayload(secretName, namespace, issuer, dnsNames, expiresAt, serialNumber, referencedBy))
+}
+
+// SendUnmanagedExpiringNotification sends a notification for an expiring
+// certificate in a TLS Secret that is not managed by cert-manager
+func (n *Notifier) SendUnmanagedExpiringNotification(ctx context.Context, secretName, namespace, issuer string, dnsNames []string, expiresAt time.Time, serialNumber string, referencedBy []string) error {
+	return n.sendNotification(ctx, newUnmanagedExpiringPayload(secretName, namespace, issuer, dnsNames, expiresAt, serialNumber, referencedBy))
+}
+
+// SendServedStaleNotification sends a notification for certificates whose
+// endpoints still serve a different certificate than the one in the Secret
+func (n *Notifier) SendServedStaleNotification(ctx context.Context, certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, serialNumber string, endpoints []EndpointStatus) error {
+	return n.sendNotification(ctx, newServedStalePayload(certName, namespace, issuer, dnsNames, expiresAt, serialNumber, endpoints))
+}
+
+// SendServedExpiringNotification sends a notification for certificates whose
+// endpoints serve a certificate that is expired or expiring soon
+func (n *Notifier) SendServedExpiringNotification(ctx context.Context, certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, serialNumber string, endpoints []EndpointStatus) error {
+	return n.sendNotification(ctx, newServedExpiringPayload(certName, namespace, issuer, dnsNames, expiresAt, serialNumber, endpoints))
+}
+
+// SendPolicyViolationNotification sends a notification for certificates that
+// violate the configured compliance policy
+func (n *Notifier) SendPolicyViolationNotification(ctx context.Context, certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, violations []string) error {
+	return n.sendNotification(ctx, newPolicyViolationPayload(certName, namespace, issuer, dnsNames, expiresAt, violations))
+}
+
+// SendRenewalWindowNotification sends an advisory for certificates whose
+// renewal window is misconfigured
+func (n *Notifier) SendRenewalWindowNotification(ctx context.Context, certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, advisories []string) error {
+	return n.sendNotification(ctx, newRenewalWindowPayload(certName, namespace, issuer, dnsNames, expiresAt, advisories))
+}
+
+// SendSecretMismatchNotification sends a notification for certificates whose
+// TLS Secret is missing or does not match the Certificate
+func (n *Notifier) SendSecretMismatchNotification(ctx context.Context, certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, serialNumber string, mismatches []string) error {
+	return n.sendNotification(ctx, newSecretMismatchPayload(certName, namespace, issuer, dnsNames, expiresAt, serialNumber, mismatches))
+}
+
+// newExpiredPayload builds the payload for an expired certificate
+func newExpiredPayload(certName, namespace, issuer string, dnsNames []string, expiresAt time.Time) NotificationPayload {
 	payload := NotificationPayload{
-		Type:      "expired",
+		Type:      TypeExpired,
+		Source:    SourceCertificate,
 		Message:   fmt.Sprintf("Certificate %s/%s has expired", namespace, certName),
 		Timestamp: time.Now(),
 	}
@@ -59,15 +286,16 @@ func (n *Notifier) SendExpiredNotification(ctx context.Context, certName, namesp
 	payload.Certificate.DNSNames = dnsNames
 	payload.Certificate.ExpiresAt = expiresAt
 
-	return n.sendNotification(ctx, payload)
+	return payload
 }
 
-// SendExpiringNotification sends a notification for certificates expiring soon
-func (n *Notifier) SendExpiringNotification(ctx context.Context, certName, namespace, issuer string, dnsNames []string, expiresAt time.Time) error {
+// newExpiringPayload builds the payload for a certificate expiring soon
+func newExpiringPayload(certName, namespace, issuer string, dnsNames []string, expiresAt time.Time) NotificationPayload {
 	daysUntilExpiry := int(time.Until(expiresAt).Hours() / 24)
 
 	payload := NotificationPayload{
-		Type:      "expiring",
+		Type:      TypeExpiring,
+		Source:    SourceCertificate,
 		Message:   fmt.Sprintf("Certificate %s/%s expires in %d days", namespace, certName, daysUntilExpiry),
 		Timestamp: time.Now(),
 	}
@@ -78,11 +306,231 @@ func (n *Notifier) SendExpiringNotification(ctx context.Context, certName, names
 	payload.Certificate.DNSNames = dnsNames
 	payload.Certificate.ExpiresAt = expiresAt
 
-	return n.sendNotification(ctx, payload)
+	return payload
+}
+
+// newChainExpiredPayload builds the payload for a certificate whose chain has an expired element
+func newChainExpiredPayload(certName, namespace, issuer string, dnsNames []string, element ChainElement) NotificationPayload {
+	payload := newExpiredPayload(certName, namespace, issuer, dnsNames, element.NotAfter)
+	payload.Message = fmt.Sprintf("Certificate %s/%s has expired: %s expired", namespace, certName, element.Description)
+	payload.ChainElement = &element
+	return payload
+}
+
+// newChainExpiringPayload builds the payload for a certificate whose chain has an element expiring soon
+func newChainExpiringPayload(certName, namespace, issuer string, dnsNames []string, element ChainElement) NotificationPayload {
+	daysUntilExpiry := int(time.Until(element.NotAfter).Hours() / 24)
+
+	payload := newExpiringPayload(certName, namespace, issuer, dnsNames, element.NotAfter)
+	payload.Message = fmt.Sprintf("Certificate %s/%s expires in %d days: %s expires first", namespace, certName, daysUntilExpiry, element.Description)
+	payload.ChainElement = &element
+	return payload
+}
+
+// newServedStalePayload builds the payload for endpoints serving a stale certificate
+func newServedStalePayload(certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, serialNumber string, endpoints []EndpointStatus) NotificationPayload {
+	payload := NotificationPayload{
+		Type:      TypeServedStale,
+		Message:   fmt.Sprintf("Certificate %s/%s was renewed but endpoints still serve the previous certificate", namespace, certName),
+		Source:    SourceCertificate,
+		Endpoints: endpoints,
+		Timestamp: time.Now(),
+	}
+
+	payload.Certificate.Name = certName
+	payload.Certificate.Namespace = namespace
+	payload.Certificate.Issuer = issuer
+	payload.Certificate.DNSNames = dnsNames
+	payload.Certificate.ExpiresAt = expiresAt
+	payload.Certificate.SerialNumber = serialNumber
+
+	return payload
+}
+
+// newServedExpiringPayload builds the payload for endpoints serving a certificate expiring soon
+func newServedExpiringPayload(certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, serialNumber string, endpoints []EndpointStatus) NotificationPayload {
+	payload := NotificationPayload{
+		Type:      TypeServedExpiring,
+		Message:   fmt.Sprintf("Endpoints of certificate %s/%s serve a certificate that is expired or expiring soon", namespace, certName),
+		Source:    SourceCertificate,
+		Endpoints: endpoints,
+		Timestamp: time.Now(),
+	}
+
+	payload.Certificate.Name = certName
+	payload.Certificate.Namespace = namespace
+	payload.Certificate.Issuer = issuer
+	payload.Certificate.DNSNames = dnsNames
+	payload.Certificate.ExpiresAt = expiresAt
+	payload.Certificate.SerialNumber = serialNumber
+
+	return payload
+}
+
+// newUnmanagedExpiredPayload builds the payload for an expired unmanaged TLS Secret
+func newUnmanagedExpiredPayload(secretName, namespace, issuer string, dnsNames []string, expiresAt time.Time, serialNumber string, referencedBy []string) NotificationPayload {
+	payload := newExpiredPayload(secretName, namespace, issuer, dnsNames, expiresAt)
+	payload.Message = fmt.Sprintf("Unmanaged TLS secret %s/%s has expired", namespace, secretName)
+	payload.Source = SourceUnmanagedSecret
+	payload.Certificate.SerialNumber = serialNumber
+	payload.ReferencedBy = referencedBy
+	return payload
+}
+
+// newUnmanagedExpiringPayload builds the payload for an unmanaged TLS Secret expiring soon
+func newUnmanagedExpiringPayload(secretName, namespace, issuer string, dnsNames []string, expiresAt time.Time, serialNumber string, referencedBy []string) NotificationPayload {
+	daysUntilExpiry := int(time.Until(expiresAt).Hours() / 24)
+
+	payload := newExpiringPayload(secretName, namespace, issuer, dnsNames, expiresAt)
+	payload.Message = fmt.Sprintf("Unmanaged TLS secret %s/%s expires in %d days", namespace, secretName, daysUntilExpiry)
+	payload.Source = SourceUnmanagedSecret
+	payload.Certificate.SerialNumber = serialNumber
+	payload.ReferencedBy = referencedBy
+	return payload
+}
+
+// newIssuerNotReadyPayload builds the payload for an issuer that is not Ready
+func newIssuerNotReadyPayload(issuer IssuerDetails, affected []CertificateRef) NotificationPayload {
+	message := fmt.Sprintf("%s is not ready", issuer)
+	if issuer.Reason != "" {
+		message = fmt.Sprintf("%s is not ready: %s", issuer, issuer.Reason)
+	}
+
+	return NotificationPayload{
+		Type:                 TypeIssuerNotReady,
+		Message:              message,
+		Issuer:               &issuer,
+		AffectedCertificates: affected,
+		Timestamp:            time.Now(),
+	}
+}
+
+// newIssuerCAExpiredPayload builds the payload for a CA issuer whose CA certificate has expired
+func newIssuerCAExpiredPayload(issuer IssuerDetails, affected []CertificateRef) NotificationPayload {
+	return NotificationPayload{
+		Type:                 TypeIssuerCAExpired,
+		Message:              fmt.Sprintf("CA certificate of %s has expired", issuer),
+		Issuer:               &issuer,
+		AffectedCertificates: affected,
+		Timestamp:            time.Now(),
+	}
+}
+
+// newIssuerCAExpiringPayload builds the payload for a CA issuer whose CA certificate expires soon
+func newIssuerCAExpiringPayload(issuer IssuerDetails, affected []CertificateRef) NotificationPayload {
+	var daysUntilExpiry int
+	if issuer.CAExpiresAt != nil {
+		daysUntilExpiry = int(time.Until(*issuer.CAExpiresAt).Hours() / 24)
+	}
+
+	return NotificationPayload{
+		Type:                 TypeIssuerCAExpiring,
+		Message:              fmt.Sprintf("CA certificate of %s expires in %d days", issuer, daysUntilExpiry),
+		Issuer:               &issuer,
+		AffectedCertificates: affected,
+		Timestamp:            time.Now(),
+	}
+}
+
+// newRenewalFailedPayload builds the payload for a certificate failing to renew
+func newRenewalFailedPayload(certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, renewal RenewalDetails) NotificationPayload {
+	payload := NotificationPayload{
+		Type:      TypeRenewalFailed,
+		Source:    SourceCertificate,
+		Message:   fmt.Sprintf("Certificate %s/%s is failing to renew: %s", namespace, certName, renewal.Summary()),
+		Renewal:   &renewal,
+		Timestamp: time.Now(),
+	}
+
+	payload.Certificate.Name = certName
+	payload.Certificate.Namespace = namespace
+	payload.Certificate.Issuer = issuer
+	payload.Certificate.DNSNames = dnsNames
+	payload.Certificate.ExpiresAt = expiresAt
+
+	return payload
+}
+
+// newPolicyViolationPayload builds the payload for a certificate violating the policy
+func newPolicyViolationPayload(certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, violations []string) NotificationPayload {
+	payload := NotificationPayload{
+		Type:      TypePolicyViolation,
+		Message:   fmt.Sprintf("Certificate %s/%s violates the certificate policy: %s", namespace, certName, strings.Join(violations, "; ")),
+		Source:    SourceCertificate,
+		Details:   violations,
+		Timestamp: time.Now(),
+	}
+
+	payload.Certificate.Name = certName
+	payload.Certificate.Namespace = namespace
+	payload.Certificate.Issuer = issuer
+	payload.Certificate.DNSNames = dnsNames
+	payload.Certificate.ExpiresAt = expiresAt
+
+	return payload
+}
+
+// newRenewalWindowPayload builds the advisory payload for a misconfigured renewal window
+func newRenewalWindowPayload(certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, advisories []string) NotificationPayload {
+	payload := NotificationPayload{
+		Type:      TypeRenewalWindowMisconfigured,
+		Message:   fmt.Sprintf("Certificate %s/%s has a misconfigured renewal window", namespace, certName),
+		Source:    SourceCertificate,
+		Details:   advisories,
+		Timestamp: time.Now(),
+	}
+
+	payload.Certificate.Name = certName
+	payload.Certificate.Namespace = namespace
+	payload.Certificate.Issuer = issuer
+	payload.Certificate.DNSNames = dnsNames
+	payload.Certificate.ExpiresAt = expiresAt
+
+	return payload
+}
+
+// newSecretMismatchPayload builds the payload for a certificate whose Secret does not match
+func newSecretMismatchPayload(certName, namespace, issuer string, dnsNames []string, expiresAt time.Time, serialNumber string, mismatches []string) NotificationPayload {
+	payload := NotificationPayload{
+		Type:      TypeSecretMismatch,
+		Source:    SourceCertificate,
+		Message:   fmt.Sprintf("Certificate %s/%s does not match its TLS secret", namespace, certName),
+		Details:   mismatches,
+		Timestamp: time.Now(),
+	}
+
+	payload.Certificate.Name = certName
+	payload.Certificate.Namespace = namespace
+	payload.Certificate.Issuer = issuer
+	payload.Certificate.DNSNames = dnsNames
+	payload.Certificate.ExpiresAt = expiresAt
+	payload.Certificate.SerialNumber = serialNumber
+
+	return payload
 }
 
 // sendNotification sends the notification to all configured webhooks
 func (n *Notifier) sendNotification(ctx context.Context, payload NotificationPayload) error {
+	payload.Cluster = clusterFromContext(ctx)
+
+	// Routed notifications only go to the route's webhooks, so that its
+	// template never changes what the configured webhooks receive
+	webhooks := n.Webhooks()
+	if route := routeFromContext(ctx); len(route.Webhooks) > 0 {
+		webhooks = route.Webhooks
+		if route.Message != nil {
+			if message, err := route.renderMessage(payload); err != nil {
+				n.logger.WithError(err).Warn("Failed to render notification message, using the default")
+			} else {
+				payload.Message = message
+			}
+		}
+	}
+
+	if n.dryRun {
+		return n.recordDryRun(payload, webhooks)
+	}
+
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal notification payload: %w", err)
@@ -91,12 +539,34 @@ func (n *Notifier) sendNotification(ctx context.Context, payload NotificationPay
 	var lastError error
 	successCount := 0
 
-	for _, webhook := range n.webhooks {
-		if err := n.sendToWebhook(ctx, webhook, jsonPayload); err != nil {
+	for _, webhook := range webhooks {
+		start := time.Now()
+		statusCode, _, err := n.sendToWebhook(ctx, webhook, jsonPayload)
+		n.recordDelivery(webhook.Name, err)
+
+		delivery := Delivery{
+			Webhook:    webhook.Name,
+			Type:       payload.Type,
+			Cluster:    payload.Cluster,
+			Namespace:  payload.Certificate.Namespace,
+			Name:       payload.Certificate.Name,
+			Success:    err == nil,
+			StatusCode: statusCode,
+			DurationMs: time.Since(start).Milliseconds(),
+			Timestamp:  start,
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		n.history.Add(delivery)
+
+		if err != nil {
 			n.logger.WithError(err).WithField("webhook", webhook.Name).Error("Failed to send notification")
+			metrics.NotificationFailed(payload.Cluster, webhook.Name, payload.Type)
 			lastError = err
 		} else {
 			successCount++
+			metrics.NotificationSent(payload.Cluster, webhook.Name, payload.Type)
 			n.logger.WithField("webhook", webhook.Name).Info("Notification sent successfully")
 		}
 	}
@@ -105,43 +575,114 @@ func (n *Notifier) sendNotification(ctx context.Context, payload NotificationPay
 		return fmt.Errorf("failed to send notification to any webhook: %w", lastError)
 	}
 
-	if successCount < len(n.webhooks) {
-		n.logger.WithField("success_count", successCount).WithField("total_webhooks", len(n.webhooks)).Warn("Some webhooks failed")
+	if successCount < len(webhooks) {
+		n.logger.WithField("success_count", successCount).WithField("total_webhooks", len(webhooks)).Warn("Some webhooks failed")
 	}
 
 	return nil
 }
 
-// sendToWebhook sends the notification to a specific webhook
-func (n *Notifier) sendToWebhook(ctx context.Context, webhook config.WebhookConfig, payload []byte) error {
+// recordDelivery records the outcome of the last delivery to a webhook
+func (n *Notifier) recordDelivery(webhookName string, err error) {
+	n.statusMutex.Lock()
+	defer n.statusMutex.Unlock()
+
+	if err == nil {
+		delete(n.lastFailures, webhookName)
+		return
+	}
+	n.lastFailures[webhookName] = deliveryFailure{err: err, at: time.Now()}
+}
+
+// Reachable reports an error when the last delivery to every webhook failed
+// within the last deliveryFailureTTL. Webhooks that have not been used yet,
+// or not recently, are considered reachable.
+func (n *Notifier) Reachable() error {
+	return n.reachable(time.Now())
+}
+
+// reachable reports whether a webhook was reachable as of now
+func (n *Notifier) reachable(now time.Time) error {
+	webhooks := n.Webhooks()
+
+	n.statusMutex.RLock()
+	defer n.statusMutex.RUnlock()
+
+	var failing []string
+	for _, webhook := range webhooks {
+		failure, ok := n.lastFailures[webhook.Name]
+		if ok && now.Sub(failure.at) < deliveryFailureTTL {
+			failing = append(failing, fmt.Sprintf("%s: %v", webhook.Name, failure.err))
+		}
+	}
+
+	if len(failing) == 0 || len(failing) < len(webhooks) {
+		return nil
+	}
+
+	sort.Strings(failing)
+	return fmt.Errorf("last delivery within %s failed for all webhooks: %s", deliveryFailureTTL, strings.Join(failing, "; "))
+}
+
+// sendToWebhook sends the notification to a specific webhook and returns
+// the response status code and the beginning of the response body
+func (n *Notifier) sendToWebhook(ctx context.Context, webhook config.WebhookConfig, payload []byte) (int, []byte, error) {
 	// Create request with timeout context
 	reqCtx, cancel := context.WithTimeout(ctx, webhook.Timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(reqCtx, "POST", webhook.URL, bytes.NewBuffer(payload))
+	req, err := newRequest(reqCtx, webhook, payload)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "cert-manager-notifier/1.0")
-
-	for key, value := range webhook.Headers {
-		req.Header.Set(key, value)
+	client, err := n.clientFor(webhook)
+	if err != nil {
+		return 0, nil, err
 	}
 
 	// Send request
-	resp, err := n.client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return 0, nil, fmt.Errorf("failed to send request: %w", redactURLError(err))
 	}
 	defer resp.Body.Close()
 
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+
 	// Check response status
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("webhook returned non-success status: %d", resp.StatusCode)
+		return resp.StatusCode, body, fmt.Errorf("webhook returned non-success status: %d", resp.StatusCode)
 	}
 
-	return nil
+	return resp.StatusCode, body, nil
+}
+
+// newRequest builds the HTTP request delivering a payload to a webhook
+func newRequest(ctx context.Context, webhook config.WebhookConfig, payload []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", webhook.URL, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, redactURLError(err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cert-manager-notifier/1.0")
+
+	for key, value := range webhook.Headers {
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
+}
+
+// redactURLError redacts the webhook URL that errors from building and
+// sending requests carry, since they end up in logs, the delivery history
+// and health checks
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = config.RedactURL(urlErr.URL)
+	}
+	return err
 }
@@ -1,9 +1,11 @@
 package webhook
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -101,3 +103,113 @@ func TestNotifier_SendNotification_FailedWebhook(t *testing.T) {
 		t.Error("Expected error, got nil")
 	}
 }
+
+func TestNotifier_SendNotification_RedactsURL(t *testing.T) {
+	// Closed server, so the connection is refused
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	webhooks := []config.WebhookConfig{
+		{
+			Name:    "test-webhook",
+			URL:     server.URL + "/services/T000/B000/XXXX",
+			Headers: map[string]string{},
+			Timeout: 5 * time.Second,
+		},
+	}
+
+	logger := logrus.NewEntry(logrus.New())
+	notifier := NewNotifier(webhooks, logger)
+
+	err := notifier.SendExpiredNotification(context.Background(), "test-cert", "default", "letsencrypt", []string{"example.com"}, time.Now())
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if strings.Contains(err.Error(), "XXXX") {
+		t.Errorf("Expected the webhook URL to be redacted, got: %v", err)
+	}
+	if err := notifier.Reachable(); err == nil || strings.Contains(err.Error(), "XXXX") {
+		t.Errorf("Expected a redacted delivery error, got: %v", err)
+	}
+}
+
+func TestNotifier_Reachable(t *testing.T) {
+	// Create test server that returns error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhooks := []config.WebhookConfig{
+		{
+			Name:    "test-webhook",
+			URL:     server.URL,
+			Headers: map[string]string{},
+			Timeout: 5 * time.Second,
+		},
+	}
+
+	logger := logrus.NewEntry(logrus.New())
+	notifier := NewNotifier(webhooks, logger)
+
+	if err := notifier.Reachable(); err != nil {
+		t.Errorf("Expected unused webhook to be reachable, got: %v", err)
+	}
+
+	_ = notifier.SendExpiredNotification(context.Background(), "test-cert", "default", "letsencrypt", []string{"example.com"}, time.Now())
+
+	if err := notifier.Reachable(); err == nil {
+		t.Error("Expected error after failed delivery, got nil")
+	}
+
+	// The notifier recovers without waiting for the next delivery
+	if err := notifier.reachable(time.Now().Add(deliveryFailureTTL)); err != nil {
+		t.Errorf("Expected an old failure to be ignored, got: %v", err)
+	}
+}
+
+func TestNotifier_DryRun(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhooks := []config.WebhookConfig{
+		{
+			Name:    "test-webhook",
+			URL:     server.URL + "/services/secret/path",
+			Headers: map[string]string{"Authorization": "Bearer secret-token"},
+			Timeout: 5 * time.Second,
+		},
+	}
+
+	logger := logrus.NewEntry(logrus.New())
+	notifier := NewNotifier(webhooks, logger)
+
+	var output bytes.Buffer
+	notifier.EnableDryRun(&output)
+
+	err := notifier.SendExpiringNotification(context.Background(), "test-cert", "default", "letsencrypt", []string{"example.com"}, time.Now().Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if requests != 0 {
+		t.Errorf("Expected no requests in dry run mode, got %d", requests)
+	}
+
+	if strings.Contains(output.String(), "secret") {
+		t.Errorf("Expected secrets to be redacted, got: %s", output.String())
+	}
+
+	if !strings.Contains(output.String(), `"type":"expiring"`) {
+		t.Errorf("Expected rendered body in output, got: %s", output.String())
+	}
+
+	deliveries := notifier.History().List()
+	if len(deliveries) != 1 || !deliveries[0].DryRun {
+		t.Errorf("Expected dry run delivery in history, got %+v", deliveries)
+	}
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+)
+
+// RenewalDetails describes a failing renewal, following the Certificate to
+// its latest CertificateRequest and, for ACME issuers, its Order and Challenges
+type RenewalDetails struct {
+	Problem            string                    `json:"problem"`
+	CertificateRequest *CertificateRequestStatus `json:"certificate_request,omitempty"`
+	Order              *OrderStatus              `json:"order,omitempty"`
+	Challenges         []ChallengeStatus         `json:"challenges,omitempty"`
+}
+
+// CertificateRequestStatus is the state of a CertificateRequest
+type CertificateRequestStatus struct {
+	Name      string    `json:"name"`
+	Reason    string    `json:"reason,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrderStatus is the state of an ACME Order
+type OrderStatus struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChallengeStatus is the state of an ACME Challenge
+type ChallengeStatus struct {
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	DNSName    string    `json:"dns_name"`
+	State      string    `json:"state,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Presented  bool      `json:"presented"`
+	Processing bool      `json:"processing"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Summary describes the most specific known cause of the failure
+func (r RenewalDetails) Summary() string {
+	for _, challenge := range r.Challenges {
+		if challenge.State == "valid" {
+			continue
+		}
+
+		summary := fmt.Sprintf("%s challenge for %s is %s", challenge.Type, challenge.DNSName, stateOrPending(challenge.State))
+		if challenge.Reason != "" {
+			summary += ": " + challenge.Reason
+		}
+		return summary
+	}
+
+	if r.Order != nil && r.Order.State != "valid" {
+		summary := fmt.Sprintf("order %s is %s", r.Order.Name, stateOrPending(r.Order.State))
+		if r.Order.Reason != "" {
+			summary += ": " + r.Order.Reason
+		}
+		return summary
+	}
+
+	if r.CertificateRequest != nil && r.CertificateRequest.Reason != "" {
+		summary := fmt.Sprintf("certificate request %s is %s", r.CertificateRequest.Name, r.CertificateRequest.Reason)
+		if r.CertificateRequest.Message != "" {
+			summary += ": " + r.CertificateRequest.Message
+		}
+		return summary
+	}
+
+	return r.Problem
+}
+
+// stateOrPending returns the ACME state, treating an unset state as pending
+func stateOrPending(state string) string {
+	if state == "" {
+		return "pending"
+	}
+	return state
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"text/template"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+// routeKey is the context key holding the route of the notifications
+type routeKey struct{}
+
+// Route directs notifications to its webhooks instead of the configured ones
+// and optionally renders their message from a template
+type Route struct {
+	Webhooks []config.WebhookConfig
+	Message  *template.Template
+}
+
+// WithRoute returns a context whose notifications follow the route
+func WithRoute(ctx context.Context, route Route) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// routeFromContext returns the route set by WithRoute, if any
+func routeFromContext(ctx context.Context) Route {
+	route, _ := ctx.Value(routeKey{}).(Route)
+	return route
+}
+
+// renderMessage renders the message of a payload from the route's template
+func (r Route) renderMessage(payload NotificationPayload) (string, error) {
+	var message strings.Builder
+	if err := r.Message.Execute(&message, payload); err != nil {
+		return "", err
+	}
+	return message.String(), nil
+}
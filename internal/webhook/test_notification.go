@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+// TestResult is the outcome of sending a test notification to a webhook
+type TestResult struct {
+	Webhook      string
+	StatusCode   int
+	Latency      time.Duration
+	ResponseBody string
+	Err          error
+}
+
+// RenderedRequest is an HTTP request as it would be sent to a webhook
+type RenderedRequest struct {
+	Webhook string          `json:"webhook"`
+	Method  string          `json:"method"`
+	URL     string          `json:"url"`
+	Headers http.Header     `json:"headers"`
+	Body    json.RawMessage `json:"body"`
+}
+
+// SamplePayloads returns a synthetic payload for each notification type
+func SamplePayloads() []NotificationPayload {
+	now := time.Now()
+	dnsNames := []string{"example.com", "www.example.com"}
+	affected := []CertificateRef{{Namespace: "cert-manager-notifier-test", Name: "example-cert"}}
+	caExpired := now.Add(-24 * time.Hour)
+	servedExpiresAt := now.Add(5 * 24 * time.Hour)
+	caExpiring := now.Add(15 * 24 * time.Hour)
+
+	payloads := []NotificationPayload{
+		newExpiredPayload("example-cert", "cert-manager-notifier-test", "letsencrypt-prod", dnsNames, now.Add(-24*time.Hour)),
+		newExpiringPayload("example-cert", "cert-manager-notifier-test", "letsencrypt-prod", dnsNames, now.Add(15*24*time.Hour)),
+		newSecretMismatchPayload("example-cert", "cert-manager-notifier-test", "letsencrypt-prod", dnsNames, now.Add(60*24*time.Hour), "0A:BC:DE",
+			[]string{"DNS names missing from the issued certificate: www.example.com"}),
+		newPolicyViolationPayload("example-cert", "cert-manager-notifier-test", "letsencrypt-prod", dnsNames, now.Add(60*24*time.Hour),
+			[]string{"RSA key size 2048 is below the minimum of 3072", "renewBefore or renewBeforePercentage is not set"}),
+		newRenewalWindowPayload("example-cert", "cert-manager-notifier-test", "letsencrypt-prod", dnsNames, now.Add(60*24*time.Hour),
+			[]string{"renewBefore 168h0m0s is shorter than the expiration threshold 720h0m0s, so expiring notifications are deferred until renewal is overdue"}),
+		newServedStalePayload("example-cert", "cert-manager-notifier-test", "letsencrypt-prod", dnsNames, now.Add(60*24*time.Hour), "0A:BC:DE",
+			[]EndpointStatus{{Address: "example.com:443", ServerName: "example.com", SerialNumber: "01:23:45", ExpiresAt: &servedExpiresAt, Stale: true}}),
+		newServedExpiringPayload("example-cert", "cert-manager-notifier-test", "letsencrypt-prod", dnsNames, now.Add(60*24*time.Hour), "0A:BC:DE",
+			[]EndpointStatus{{Address: "example.com:443", ServerName: "example.com", SerialNumber: "01:23:45", ExpiresAt: &servedExpiresAt, Stale: true}}),
+		newRenewalFailedPayload("example-cert", "cert-manager-notifier-test", "letsencrypt-prod", dnsNames, now.Add(15*24*time.Hour), RenewalDetails{
+			Problem:            "issuance failed at " + now.Add(-time.Hour).UTC().Format(time.RFC3339),
+			CertificateRequest: &CertificateRequestStatus{Name: "example-cert-2", Reason: "Pending", CreatedAt: now.Add(-2 * time.Hour)},
+			Order:              &OrderStatus{Name: "example-cert-2-1234", State: "pending", CreatedAt: now.Add(-2 * time.Hour)},
+			Challenges: []ChallengeStatus{{
+				Name:      "example-cert-2-1234-5678",
+				Type:      "HTTP-01",
+				DNSName:   "example.com",
+				State:     "pending",
+				Reason:    "Waiting for HTTP-01 challenge propagation: wrong status code '404', expected '200'",
+				Presented: true,
+				CreatedAt: now.Add(-2 * time.Hour),
+			}},
+		}),
+		newIssuerNotReadyPayload(IssuerDetails{
+			Kind:    "ClusterIssuer",
+			Name:    "letsencrypt-prod",
+			Reason:  "ErrRegisterACMEAccount",
+			Message: "Failed to register ACME account",
+		}, affected),
+		newIssuerCAExpiredPayload(IssuerDetails{Kind: "Issuer", Name: "internal-ca", Namespace: "cert-manager-notifier-test", CASecret: "internal-ca", CAExpiresAt: &caExpired}, affected),
+		newIssuerCAExpiringPayload(IssuerDetails{Kind: "Issuer", Name: "internal-ca", Namespace: "cert-manager-notifier-test", CASecret: "internal-ca", CAExpiresAt: &caExpiring}, affected),
+	}
+
+	for i := range payloads {
+		payloads[i].Message = "[TEST] " + payloads[i].Message
+	}
+	return payloads
+}
+
+// SendTest sends a payload to the named webhook, or to all webhooks if name
+// is empty, and reports the outcome per destination. Test notifications are
+// not recorded in the delivery history.
+func (n *Notifier) SendTest(ctx context.Context, payload NotificationPayload, name string) ([]TestResult, error) {
+	webhooks, err := n.selectWebhooks(name)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification payload: %w", err)
+	}
+
+	results := make([]TestResult, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		start := time.Now()
+		statusCode, body, err := n.sendToWebhook(ctx, webhook, jsonPayload)
+		results = append(results, TestResult{
+			Webhook:      webhook.Name,
+			StatusCode:   statusCode,
+			Latency:      time.Since(start),
+			ResponseBody: string(body),
+			Err:          err,
+		})
+	}
+
+	return results, nil
+}
+
+// RenderRequests renders the unredacted requests that would deliver a payload to the
+// named webhook, or to all webhooks if name is empty
+func (n *Notifier) RenderRequests(payload NotificationPayload, name string) ([]RenderedRequest, error) {
+	webhooks, err := n.selectWebhooks(name)
+	if err != nil {
+		return nil, err
+	}
+	return renderRequests(payload, webhooks)
+}
+
+// renderRequests renders the unredacted requests that would deliver a payload to webhooks
+func renderRequests(payload NotificationPayload, webhooks []config.WebhookConfig) ([]RenderedRequest, error) {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification payload: %w", err)
+	}
+
+	rendered := make([]RenderedRequest, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		req, err := newRequest(context.Background(), webhook, jsonPayload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request for %s: %w", webhook.Name, err)
+		}
+
+		rendered = append(rendered, RenderedRequest{
+			Webhook: webhook.Name,
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: req.Header,
+			Body:    jsonPayload,
+		})
+	}
+
+	return rendered, nil
+}
+
+// selectWebhooks returns the named webhook, or all webhooks if name is empty
+func (n *Notifier) selectWebhooks(name string) ([]config.WebhookConfig, error) {
+	webhooks := n.Webhooks()
+	if name == "" {
+		return webhooks, nil
+	}
+
+	for _, webhook := range webhooks {
+		if webhook.Name == name {
+			return []config.WebhookConfig{webhook}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown webhook %q", name)
+}
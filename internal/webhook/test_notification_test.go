@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+func TestNotifier_SendTest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("accepted"))
+	}))
+	defer server.Close()
+
+	webhooks := []config.WebhookConfig{
+		{Name: "webhook-1", URL: server.URL, Headers: map[string]string{}, Timeout: 5 * time.Second},
+		{Name: "webhook-2", URL: server.URL, Headers: map[string]string{}, Timeout: 5 * time.Second},
+	}
+
+	notifier := NewNotifier(webhooks, logrus.NewEntry(logrus.New()))
+	payload := SamplePayloads()[0]
+
+	results, err := notifier.SendTest(context.Background(), payload, "webhook-2")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(results) != 1 || results[0].Webhook != "webhook-2" {
+		t.Fatalf("Expected a single result for webhook-2, got %+v", results)
+	}
+
+	if results[0].StatusCode != http.StatusAccepted || results[0].ResponseBody != "accepted" || results[0].Err != nil {
+		t.Errorf("Unexpected test result: %+v", results[0])
+	}
+
+	if len(notifier.History().List()) != 0 {
+		t.Error("Expected test notifications not to be recorded in history")
+	}
+
+	if _, err := notifier.SendTest(context.Background(), payload, "missing"); err == nil {
+		t.Error("Expected error for unknown webhook, got nil")
+	}
+}
+
+func TestNotifier_RenderRequests(t *testing.T) {
+	webhooks := []config.WebhookConfig{
+		{Name: "webhook-1", URL: "https://example.com/hook", Headers: map[string]string{"X-Custom": "value"}, Timeout: 5 * time.Second},
+	}
+
+	notifier := NewNotifier(webhooks, logrus.NewEntry(logrus.New()))
+
+	rendered, err := notifier.RenderRequests(SamplePayloads()[1], "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(rendered) != 1 {
+		t.Fatalf("Expected 1 rendered request, got %d", len(rendered))
+	}
+
+	if rendered[0].Method != http.MethodPost || rendered[0].Headers.Get("X-Custom") != "value" {
+		t.Errorf("Unexpected rendered request: %+v", rendered[0])
+	}
+
+	if !strings.Contains(string(rendered[0].Body), `"type":"expiring"`) {
+		t.Errorf("Expected expiring payload in body, got %s", rendered[0].Body)
+	}
+}
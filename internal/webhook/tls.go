@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+// tlsClientKey identifies the HTTP client built for the TLS settings of a
+// webhook. The fingerprint is part of the key, so webhooks sharing a name,
+// such as a configured webhook and a policy destination, never evict each
+// other's client.
+type tlsClientKey struct {
+	name        string
+	fingerprint string
+}
+
+// clientFor returns the HTTP client delivering to a webhook: the shared
+// client, or one with the webhook's own TLS settings. Those clients are
+// kept per webhook and settings, so a renewed client certificate gets a new
+// client, and SetWebhooks drops the clients no webhook uses any more.
+func (n *Notifier) clientFor(webhook config.WebhookConfig) (*http.Client, error) {
+	if !webhook.TLS.Enabled() {
+		return n.client, nil
+	}
+
+	key := tlsClientKey{name: webhook.Name, fingerprint: webhook.TLS.Fingerprint()}
+
+	n.tlsMutex.Lock()
+	defer n.tlsMutex.Unlock()
+
+	if client, ok := n.tlsClients[key]; ok {
+		return client, nil
+	}
+
+	tlsConfig, err := webhook.TLS.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   n.client.Timeout,
+	}
+	n.tlsClients[key] = client
+	return client, nil
+}
+
+// pruneClients drops the TLS clients of webhooks that are no longer
+// configured or whose settings changed. Only configured webhooks have TLS
+// settings, so the clients of any other webhook are stale.
+func (n *Notifier) pruneClients(webhooks []config.WebhookConfig) {
+	current := make(map[tlsClientKey]bool, len(webhooks))
+	for _, webhook := range webhooks {
+		if webhook.TLS.Enabled() {
+			current[tlsClientKey{name: webhook.Name, fingerprint: webhook.TLS.Fingerprint()}] = true
+		}
+	}
+
+	n.tlsMutex.Lock()
+	defer n.tlsMutex.Unlock()
+
+	for key, client := range n.tlsClients {
+		if !current[key] {
+			client.CloseIdleConnections()
+			delete(n.tlsClients, key)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wiruzman/cert-manager-notifier/internal/certtest"
+	"github.com/wiruzman/cert-manager-notifier/internal/config"
+)
+
+// newClientCertificate creates a self-signed client certificate and key as PEM
+func newClientCertificate(t *testing.T, commonName string) (*x509.Certificate, string, string) {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, key, certPEM := certtest.Issue(t, template, nil, nil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return cert, string(certPEM), string(keyPEM)
+}
+
+func TestNotifier_SendNotification_MutualTLS(t *testing.T) {
+	clientCert, certPEM, keyPEM := newClientCertificate(t, "cert-manager-notifier")
+	rotatedCert, rotatedCertPEM, rotatedKeyPEM := newClientCertificate(t, "cert-manager-notifier-rotated")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	clientCAs.AddCert(rotatedCert)
+
+	var clients []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clients = append(clients, r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	webhooks := []config.WebhookConfig{
+		{
+			Name:    "internal",
+			URL:     server.URL,
+			Headers: map[string]string{},
+			Timeout: 5 * time.Second,
+			TLS: config.TLSConfig{
+				CA:         caPEM,
+				Cert:       certPEM,
+				Key:        keyPEM,
+				ServerName: "example.com",
+				MinVersion: "1.3",
+			},
+		},
+	}
+
+	logger := logrus.NewEntry(logrus.New())
+	notifier := NewNotifier(webhooks, logger)
+
+	ctx := context.Background()
+	if err := notifier.SendExpiredNotification(ctx, "test-cert", "default", "letsencrypt", []string{"example.com"}, time.Now()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// A renewed client certificate is picked up
+	webhooks[0].TLS.Cert = rotatedCertPEM
+	webhooks[0].TLS.Key = rotatedKeyPEM
+	notifier.SetWebhooks(webhooks)
+	if err := notifier.SendExpiredNotification(ctx, "test-cert", "default", "letsencrypt", []string{"example.com"}, time.Now()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(clients) != 2 || clients[0] != "cert-manager-notifier" || clients[1] != "cert-manager-notifier-rotated" {
+		t.Errorf("Expected both client certificates to be presented, got %v", clients)
+	}
+	if len(notifier.tlsClients) != 1 {
+		t.Errorf("Expected the client of the previous certificate to be dropped, got %d clients", len(notifier.tlsClients))
+	}
+
+	// Without the client certificate the server rejects the connection
+	webhooks[0].TLS.Cert = ""
+	webhooks[0].TLS.Key = ""
+	notifier.SetWebhooks(webhooks)
+	if err := notifier.SendExpiredNotification(ctx, "test-cert", "default", "letsencrypt", []string{"example.com"}, time.Now()); err == nil {
+		t.Error("Expected an error without a client certificate, got nil")
+	}
+
+	// Removing the webhook drops its client
+	notifier.SetWebhooks(nil)
+	if len(notifier.tlsClients) != 0 {
+		t.Errorf("Expected no clients after removing the webhook, got %d", len(notifier.tlsClients))
+	}
+}
+
+func TestNotifier_SendNotification_UntrustedServer(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhooks := []config.WebhookConfig{
+		{
+			Name:    "internal",
+			URL:     server.URL,
+			Headers: map[string]string{},
+			Timeout: 5 * time.Second,
+		},
+	}
+
+	logger := logrus.NewEntry(logrus.New())
+	notifier := NewNotifier(webhooks, logger)
+
+	if err := notifier.SendExpiredNotification(context.Background(), "test-cert", "default", "letsencrypt", []string{"example.com"}, time.Now()); err == nil {
+		t.Error("Expected an error for a server signed by an unknown CA, got nil")
+	}
+}